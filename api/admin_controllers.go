@@ -0,0 +1,170 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/v3-Swampy/points-service/model"
+	"github.com/v3-Swampy/points-service/service"
+)
+
+// maxPoolWeight is the exclusive upper bound of pool weight in type of decimal(6,3).
+var maxPoolWeight = decimal.NewFromInt(1000)
+
+// hasMaxDecimals checks whether the given value has at most n decimals.
+func hasMaxDecimals(value decimal.Decimal, n int32) bool {
+	return value.Equal(value.Truncate(n))
+}
+
+// upsertPoolWeight upserts the trade and liquidity weights of pool.
+//
+//	@Summary		Upsert pool weight
+//	@Description	Upsert the trade and liquidity weights of pool, where zero value will not be updated for existing pool.
+//	@Description	Authenticated by X-Api-Key header, or EIP-712 signed request of allowlisted signer.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Api-Key				header		string								false	"Admin API key"
+//	@Param			request					body		model.AdminPoolWeightRequest		true	"Pool weight request"
+//	@Success		200						{object}	api.BusinessError					"Pool weight updated"
+//	@Failure		600						{object}	api.BusinessError{data=string}		"Internal server error"
+//	@Router			/admin/pools/weight		[post]
+func (controller *Controller) upsertPoolWeight(c *gin.Context) (any, error) {
+	var input model.AdminPoolWeightRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	if !common.IsHexAddress(input.Pool) {
+		return nil, api.ErrValidationStrf("Invalid hex address of pool %v", input.Pool)
+	}
+
+	for _, v := range []decimal.Decimal{input.TradeWeight, input.LiquidityWeight} {
+		if v.IsNegative() || v.GreaterThanOrEqual(maxPoolWeight) || !hasMaxDecimals(v, 3) {
+			return nil, api.ErrValidationStrf("Invalid weight %v, which should be in range [0, 1000) with a maximum of three decimal", v)
+		}
+	}
+
+	if input.TradeWeight.IsZero() && input.LiquidityWeight.IsZero() {
+		return nil, api.ErrValidationStr("At least one of tradeWeight or liquidityWeight is required")
+	}
+
+	op := service.AuditOperator{Operator: MustGetOperator(c), Reason: input.Reason}
+
+	return nil, controller.services.Audit.UpsertPoolWeight(op, input.Pool, input.TradeWeight, input.LiquidityWeight)
+}
+
+// insertUserPoints inserts a new user with points.
+//
+//	@Summary		Insert user points
+//	@Description	Insert a new user with trade and liquidity points.
+//	@Description	Authenticated by X-Api-Key header, or EIP-712 signed request of allowlisted signer.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Api-Key		header		string							false	"Admin API key"
+//	@Param			request			body		model.AdminUserPointsRequest	true	"User points request"
+//	@Success		200				{object}	api.BusinessError{data=uint64}	"Id of inserted user"
+//	@Failure		600				{object}	api.BusinessError{data=string}	"Internal server error"
+//	@Router			/admin/users	[post]
+func (controller *Controller) insertUserPoints(c *gin.Context) (any, error) {
+	input, err := bindUserPointsRequest(c, controller.services.User.Precision(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	op := service.AuditOperator{Operator: MustGetOperator(c), Reason: input.Reason}
+
+	return controller.services.Audit.AddUser(op, input.User, input.TradePoints, input.LiquidityPoints)
+}
+
+// updateUserPoints increases or decreases user points.
+//
+//	@Summary		Update user points
+//	@Description	Increase or decrease the trade and liquidity points of user by delta values, where negative value
+//	@Description	means decrease. Points cannot be negative after update.
+//	@Description	Authenticated by X-Api-Key header, or EIP-712 signed request of allowlisted signer.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Api-Key				header		string							false	"Admin API key"
+//	@Param			request					body		model.AdminUserPointsRequest	true	"User points delta request"
+//	@Success		200						{object}	api.BusinessError				"User points updated"
+//	@Failure		600						{object}	api.BusinessError{data=string}	"Internal server error"
+//	@Router			/admin/users/points		[post]
+func (controller *Controller) updateUserPoints(c *gin.Context) (any, error) {
+	input, err := bindUserPointsRequest(c, controller.services.User.Precision(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.TradePoints.IsZero() && input.LiquidityPoints.IsZero() {
+		return nil, api.ErrValidationStr("At least one of tradePoints or liquidityPoints is required")
+	}
+
+	op := service.AuditOperator{Operator: MustGetOperator(c), Reason: input.Reason}
+
+	return nil, controller.services.Audit.DeltaUpdateUser(op, input.User, input.TradePoints, input.LiquidityPoints)
+}
+
+// overrideToken overrides the logo URL and display symbol of token.
+//
+//	@Summary		Override token
+//	@Description	Override the logo URL and display symbol of token, where empty value clears the override.
+//	@Description	Authenticated by X-Api-Key header, or EIP-712 signed request of allowlisted signer.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Api-Key				header		string								false	"Admin API key"
+//	@Param			request					body		model.AdminTokenOverrideRequest		true	"Token override request"
+//	@Success		200						{object}	api.BusinessError					"Token overridden"
+//	@Failure		600						{object}	api.BusinessError{data=string}		"Internal server error"
+//	@Router			/admin/tokens/override	[post]
+func (controller *Controller) overrideToken(c *gin.Context) (any, error) {
+	var input model.AdminTokenOverrideRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	if !common.IsHexAddress(input.Token) {
+		return nil, api.ErrValidationStrf("Invalid hex address of token %v", input.Token)
+	}
+
+	input.LogoURL = strings.TrimSpace(input.LogoURL)
+	input.DisplaySymbol = strings.TrimSpace(input.DisplaySymbol)
+	if err := service.ValidateTokenOverrides(input.LogoURL, input.DisplaySymbol); err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	op := service.AuditOperator{Operator: MustGetOperator(c), Reason: input.Reason}
+	token := common.HexToAddress(input.Token).String()
+
+	return nil, controller.services.Audit.SetTokenOverrides(op, token, input.LogoURL, input.DisplaySymbol)
+}
+
+func bindUserPointsRequest(c *gin.Context, precision service.PrecisionConfig, allowNegative bool) (*model.AdminUserPointsRequest, error) {
+	var input model.AdminUserPointsRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	if !common.IsHexAddress(input.User) {
+		return nil, api.ErrValidationStrf("Invalid hex address of user %v", input.User)
+	}
+
+	if !hasMaxDecimals(input.TradePoints, precision.Trade) || (!allowNegative && input.TradePoints.IsNegative()) {
+		return nil, api.ErrValidationStrf("Invalid trade points %v. Only numbers are supported, with a maximum of %v decimals", input.TradePoints, precision.Trade)
+	}
+
+	if !hasMaxDecimals(input.LiquidityPoints, precision.Liquidity) || (!allowNegative && input.LiquidityPoints.IsNegative()) {
+		return nil, api.ErrValidationStrf("Invalid liquidity points %v. Only numbers are supported, with a maximum of %v decimals", input.LiquidityPoints, precision.Liquidity)
+	}
+
+	return &input, nil
+}
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/Conflux-Chain/go-conflux-util/api/middleware"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/v3-Swampy/points-service/service"
+)
+
+// HTTP headers of signed request.
+const (
+	HeaderSigner    = "X-Signer"
+	HeaderNonce     = "X-Nonce"
+	HeaderDeadline  = "X-Deadline"
+	HeaderSignature = "X-Signature"
+)
+
+// HeaderApiKey is the HTTP header of admin API key.
+const HeaderApiKey = "X-Api-Key"
+
+// Gin context keys set by authentication middlewares.
+const (
+	ContextKeySigner   = "signer"   // recovered signer address in type of common.Address
+	ContextKeyOperator = "operator" // admin operator name or signer address in type of string
+)
+
+type AuthConfig struct {
+	Signature SignatureConfig
+	Admin     AdminConfig
+}
+
+// AdminConfig is the admin authentication configurations, which allows API key or EIP-712 signed request.
+type AdminConfig struct {
+	ApiKeys map[string]string // operator name => API key
+	Signers []string          // allowlist of signer addresses
+}
+
+// operatorByApiKey returns the operator name of given API key, or false if API key not configured.
+func (config *AdminConfig) operatorByApiKey(key string) (string, bool) {
+	for name, v := range config.ApiKeys {
+		if len(v) > 0 && subtle.ConstantTimeCompare([]byte(v), []byte(key)) == 1 {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+func (config *AdminConfig) isSignerAllowed(signer common.Address) bool {
+	for _, v := range config.Signers {
+		if common.IsHexAddress(v) && common.HexToAddress(v) == signer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SignatureConfig is the EIP-712 domain and validity configurations of signed request.
+type SignatureConfig struct {
+	DomainName    string        `default:"Points Service"`
+	DomainVersion string        `default:"1"`
+	ChainId       int64         `default:"1030"` // Conflux eSpace mainnet by default
+	MaxValidity   time.Duration `default:"10m"`  // maximum duration allowed between now and deadline
+
+	// PathPrefix is prepended to the request path to sign, e.g. /points if served behind a reverse proxy that
+	// strips the prefix of public path /points/api, so that clients sign the public path as requested.
+	PathPrefix string
+}
+
+// signedRequestTypes is the EIP-712 types of signed request, where body is the raw HTTP request body,
+// and path is the URL path without query, e.g. /api/actions/referral, along with the configured path prefix if
+// any, e.g. /points/api/actions/referral.
+var signedRequestTypes = apitypes.Types{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+	},
+	"Request": {
+		{Name: "method", Type: "string"},
+		{Name: "path", Type: "string"},
+		{Name: "body", Type: "bytes"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deadline", Type: "uint256"},
+	},
+}
+
+type signedRequest struct {
+	Signer    common.Address
+	Nonce     uint64
+	Deadline  int64
+	Signature string
+
+	Method string
+	Path   string
+	Body   []byte
+}
+
+// hash returns the EIP-712 hash to sign for the request.
+func (req *signedRequest) hash(config SignatureConfig) ([]byte, error) {
+	typedData := apitypes.TypedData{
+		Types:       signedRequestTypes,
+		PrimaryType: "Request",
+		Domain: apitypes.TypedDataDomain{
+			Name:    config.DomainName,
+			Version: config.DomainVersion,
+			ChainId: math.NewHexOrDecimal256(config.ChainId),
+		},
+		Message: apitypes.TypedDataMessage{
+			"method":   req.Method,
+			"path":     req.Path,
+			"body":     req.Body,
+			"nonce":    new(big.Int).SetUint64(req.Nonce),
+			"deadline": big.NewInt(req.Deadline),
+		},
+	}
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+
+	return hash, err
+}
+
+func parseSignedRequest(c *gin.Context, pathPrefix string) (*signedRequest, error) {
+	req := signedRequest{
+		Signature: c.GetHeader(HeaderSignature),
+		Method:    c.Request.Method,
+		Path:      pathPrefix + c.Request.URL.Path,
+	}
+
+	if signer := c.GetHeader(HeaderSigner); common.IsHexAddress(signer) {
+		req.Signer = common.HexToAddress(signer)
+	} else {
+		return nil, errors.Errorf("Invalid %v header %v", HeaderSigner, signer)
+	}
+
+	var err error
+	if req.Nonce, err = strconv.ParseUint(c.GetHeader(HeaderNonce), 10, 64); err != nil {
+		return nil, errors.WithMessagef(err, "Invalid %v header", HeaderNonce)
+	}
+
+	if req.Deadline, err = strconv.ParseInt(c.GetHeader(HeaderDeadline), 10, 64); err != nil {
+		return nil, errors.WithMessagef(err, "Invalid %v header", HeaderDeadline)
+	}
+
+	if len(req.Signature) == 0 {
+		return nil, errors.Errorf("%v header not specified", HeaderSignature)
+	}
+
+	// read body and restore it for later binding
+	if c.Request.Body != nil {
+		if req.Body, err = io.ReadAll(c.Request.Body); err != nil {
+			return nil, errors.WithMessage(err, "Failed to read request body")
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(req.Body))
+	}
+
+	return &req, nil
+}
+
+// verifySignedRequest verifies the EIP-712 signature and deadline of request, and returns the signed request.
+func verifySignedRequest(c *gin.Context, config SignatureConfig) (*signedRequest, error) {
+	req, err := parseSignedRequest(c, config.PathPrefix)
+	if err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	now := time.Now()
+	if deadline := time.Unix(req.Deadline, 0); deadline.Before(now) {
+		return nil, api.ErrValidationStr("Signed request expired")
+	} else if deadline.Sub(now) > config.MaxValidity {
+		return nil, api.ErrValidationStrf("Deadline exceeds the maximum validity %v", config.MaxValidity)
+	}
+
+	hash, err := req.hash(config)
+	if err != nil {
+		return nil, api.ErrValidation(errors.WithMessage(err, "Failed to hash typed data"))
+	}
+
+	signer, err := recoverSigner(hash, req.Signature)
+	if err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	if signer != req.Signer {
+		return nil, api.ErrValidationStr("Signature not signed by signer")
+	}
+
+	return req, nil
+}
+
+// SignatureRequired returns a middleware to authenticate EIP-712 signed request, which rejects replayed
+// requests by nonce and expired requests by deadline. If authenticated, the recovered signer address is
+// injected into gin context with key ContextKeySigner.
+func SignatureRequired(config SignatureConfig, nonces *service.SignedRequestService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := verifySignedRequest(c, config)
+		if err == nil {
+			err = nonces.Consume(req.Signer.String(), req.Nonce, req.Deadline, req.Method, req.Path)
+		}
+
+		if err != nil {
+			middleware.ResponseError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextKeySigner, req.Signer)
+		c.Next()
+	}
+}
+
+// MustGetSigner returns the signer address injected by SignatureRequired middleware.
+func MustGetSigner(c *gin.Context) common.Address {
+	return c.MustGet(ContextKeySigner).(common.Address)
+}
+
+// AdminRequired returns a middleware to authenticate admin by API key in header X-Api-Key, or by EIP-712 signed
+// request from allowlisted signers. If authenticated, the operator name (or signer address) is injected into gin
+// context with key ContextKeyOperator.
+func AdminRequired(config AuthConfig, nonces *service.SignedRequestService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		operator, err := authenticateAdmin(c, config, nonces)
+		if err != nil {
+			middleware.ResponseError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Set(ContextKeyOperator, operator)
+		c.Next()
+	}
+}
+
+func authenticateAdmin(c *gin.Context, config AuthConfig, nonces *service.SignedRequestService) (string, error) {
+	if key := c.GetHeader(HeaderApiKey); len(key) > 0 {
+		if operator, ok := config.Admin.operatorByApiKey(key); ok {
+			return operator, nil
+		}
+
+		return "", api.ErrValidationStr("Invalid API key")
+	}
+
+	req, err := verifySignedRequest(c, config.Signature)
+	if err != nil {
+		return "", err
+	}
+
+	if !config.Admin.isSignerAllowed(req.Signer) {
+		return "", api.ErrValidationStrf("Signer %v not allowed", req.Signer)
+	}
+
+	if err = nonces.Consume(req.Signer.String(), req.Nonce, req.Deadline, req.Method, req.Path); err != nil {
+		return "", err
+	}
+
+	return req.Signer.String(), nil
+}
+
+// MustGetOperator returns the admin operator injected by AdminRequired middleware.
+func MustGetOperator(c *gin.Context) string {
+	return c.MustGet(ContextKeyOperator).(string)
+}
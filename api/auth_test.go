@@ -0,0 +1,77 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+	"github.com/mcuadros/go-defaults"
+)
+
+// newSignedContext creates a gin context of request to path, which is signed for the given signed path.
+func newSignedContext(t *testing.T, config SignatureConfig, path, signedPath string) *gin.Context {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	body := `{"referrer":"0x0000000000000000000000000000000000000001"}`
+	req := signedRequest{
+		Signer:   crypto.PubkeyToAddress(key.PublicKey),
+		Nonce:    1,
+		Deadline: time.Now().Add(time.Minute).Unix(),
+		Method:   http.MethodPost,
+		Path:     signedPath,
+		Body:     []byte(body),
+	}
+
+	hash, err := req.hash(config)
+	if err != nil {
+		t.Fatalf("Failed to hash request: %v", err)
+	}
+
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatalf("Failed to sign request: %v", err)
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	c.Request.Header.Set(HeaderSigner, req.Signer.String())
+	c.Request.Header.Set(HeaderNonce, fmt.Sprint(req.Nonce))
+	c.Request.Header.Set(HeaderDeadline, fmt.Sprint(req.Deadline))
+	c.Request.Header.Set(HeaderSignature, hexutil.Encode(sig))
+
+	return c
+}
+
+func TestVerifySignedRequestPathPrefix(t *testing.T) {
+	var config SignatureConfig
+	defaults.SetDefaults(&config)
+
+	// signed path as served
+	c := newSignedContext(t, config, "/api/actions/referral", "/api/actions/referral")
+	if _, err := verifySignedRequest(c, config); err != nil {
+		t.Fatalf("Failed to verify signed request: %v", err)
+	}
+
+	// signed public path behind reverse proxy that strips prefix
+	config.PathPrefix = "/points"
+	c = newSignedContext(t, config, "/api/actions/referral", "/points/api/actions/referral")
+	if _, err := verifySignedRequest(c, config); err != nil {
+		t.Fatalf("Failed to verify signed request with path prefix: %v", err)
+	}
+
+	c = newSignedContext(t, config, "/api/actions/referral", "/api/actions/referral")
+	if _, err := verifySignedRequest(c, config); err == nil {
+		t.Fatal("Expected error to verify request signed without path prefix")
+	}
+}
@@ -1,18 +1,23 @@
 package api
 
 import (
+	"strings"
+	"time"
+
 	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
 	"github.com/v3-Swampy/points-service/model"
 	"github.com/v3-Swampy/points-service/service"
 )
 
 type Controller struct {
-	services service.Services
+	services  service.Services
+	signature SignatureConfig
 }
 
-func NewController(services service.Services) *Controller {
-	return &Controller{services}
+func NewController(services service.Services, signature SignatureConfig) *Controller {
+	return &Controller{services, signature}
 }
 
 // listUsers returns users in pagination view.
@@ -25,7 +30,7 @@ func NewController(services service.Services) *Controller {
 //	@Param			offset		query		int																		false	"The number of skipped records, usually it's pageSize * (pageNumber - 1)"	minimum(0)				default(0)
 //	@Param			limit		query		int																		true	"The number of records displayed on the page"								minimum(1)				maximum(100)
 //	@Param			sort		query		string																	false	"Sort in ASC or DESC order by sortField"									Enums(asc, desc)		default(desc)
-//	@Param			sortField	query		string																	false	"The field used for sorting. The value is trade or liquidity"				Enums(trade, liquidity)	default(trade)
+//	@Param			sortField	query		string																	false	"The field used for sorting. The value is trade, liquidity or referral"		Enums(trade, liquidity, referral)	default(trade)
 //	@Success		200			{object}	api.BusinessError{data=model.PagingResultWithUpdatedAt[model.UserInfo]}	"Paged users"
 //	@Failure		600			{object}	api.BusinessError{data=string}											"Internal server error"
 //	@Router			/users		[get]
@@ -47,6 +52,7 @@ func (controller *Controller) listUsers(c *gin.Context) (any, error) {
 			Address:         u.Address,
 			TradePoints:     u.TradePoints,
 			LiquidityPoints: u.LiquidityPoints,
+			ReferralPoints:  u.ReferralPoints,
 		}
 		users = append(users, user)
 	}
@@ -112,3 +118,268 @@ func (controller *Controller) listPools(c *gin.Context) (any, error) {
 		Items: pools,
 	}, nil
 }
+
+// getPool returns the pool detail.
+//
+//	@Summary		Get pool
+//	@Description	Get pool detail, including token metadata, fee tier, accumulated points and current weights.
+//	@Tags			Pool
+//	@Accept			json
+//	@Produce		json
+//	@Param			address				path		string										true	"Pool address"
+//	@Success		200					{object}	api.BusinessError{data=model.PoolDetail}	"Pool detail"
+//	@Failure		600					{object}	api.BusinessError{data=string}				"Internal server error"
+//	@Router			/pools/{address}	[get]
+func (controller *Controller) getPool(c *gin.Context) (any, error) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		return nil, api.ErrValidationStrf("Invalid hex address %v", address)
+	}
+
+	return controller.services.Pool.Get(common.HexToAddress(address).String())
+}
+
+// getPoolTvlHistory returns the TVL history of pool.
+//
+//	@Summary		Get pool TVL history
+//	@Description	Get the latest TVL snapshots of pool in time range, which are in ascending order of timestamp.
+//	@Tags			Pool
+//	@Accept			json
+//	@Produce		json
+//	@Param			address							path		string										true	"Pool address"
+//	@Param			from							query		int											false	"Start unix timestamp in seconds (inclusive)"				minimum(0)	default(0)
+//	@Param			to								query		int											false	"End unix timestamp in seconds (inclusive), 0 means now"	minimum(0)	default(0)
+//	@Param			limit							query		int											false	"The maximum number of records"								minimum(1)	maximum(1000)	default(100)
+//	@Success		200								{object}	api.BusinessError{data=[]model.PoolTvlInfo}	"Pool TVL history"
+//	@Failure		600								{object}	api.BusinessError{data=string}				"Internal server error"
+//	@Router			/pools/{address}/tvl-history	[get]
+func (controller *Controller) getPoolTvlHistory(c *gin.Context) (any, error) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		return nil, api.ErrValidationStrf("Invalid hex address %v", address)
+	}
+
+	var input model.HistoryRequest
+	if err := c.ShouldBind(&input); err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	return controller.services.Pool.ListTvlHistory(common.HexToAddress(address).String(), input)
+}
+
+// getPoolMetrics returns the metrics of pool snapshots.
+//
+//	@Summary		Get pool metrics
+//	@Description	Get the latest metrics of pool snapshots in time range, including USD volume, liquidity value-seconds,
+//	@Description	unique traders and liquidity providers, TVL and token prices. Records are in ascending order of timestamp.
+//	@Tags			Pool
+//	@Accept			json
+//	@Produce		json
+//	@Param			address						path		string											true	"Pool address"
+//	@Param			from						query		int												false	"Start unix timestamp in seconds (inclusive)"				minimum(0)	default(0)
+//	@Param			to							query		int												false	"End unix timestamp in seconds (inclusive), 0 means now"	minimum(0)	default(0)
+//	@Param			limit						query		int												false	"The maximum number of records"								minimum(1)	maximum(1000)	default(100)
+//	@Success		200							{object}	api.BusinessError{data=[]model.PoolSnapshot}	"Pool metrics"
+//	@Failure		600							{object}	api.BusinessError{data=string}					"Internal server error"
+//	@Router			/pools/{address}/metrics	[get]
+func (controller *Controller) getPoolMetrics(c *gin.Context) (any, error) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		return nil, api.ErrValidationStrf("Invalid hex address %v", address)
+	}
+
+	var input model.HistoryRequest
+	if err := c.ShouldBind(&input); err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	return controller.services.Pool.ListSnapshots(common.HexToAddress(address).String(), input)
+}
+
+// listTokens returns all tokens of tracked pools.
+//
+//	@Summary		List tokens
+//	@Description	List all tokens of tracked pools with the latest USD price and total TVL, which are in descending order of TVL.
+//	@Description	Logo URL and display symbol are manually overridden, and empty if not overridden.
+//	@Tags			Token
+//	@Accept			json
+//	@Produce		json
+//	@Success		200			{object}	api.BusinessError{data=[]model.TokenInfo}	"Tokens"
+//	@Failure		600			{object}	api.BusinessError{data=string}				"Internal server error"
+//	@Router			/tokens		[get]
+func (controller *Controller) listTokens(c *gin.Context) (any, error) {
+	return controller.services.Token.List()
+}
+
+// getTokenPrices returns the price history of token.
+//
+//	@Summary		Get token prices
+//	@Description	Get the latest USD prices of token in time range, which were used to evaluate trade and liquidity values
+//	@Description	of snapshots. Records are in ascending order of timestamp.
+//	@Tags			Token
+//	@Accept			json
+//	@Produce		json
+//	@Param			address						path		string												true	"Token address"
+//	@Param			from						query		int													false	"Start unix timestamp in seconds (inclusive)"				minimum(0)	default(0)
+//	@Param			to							query		int													false	"End unix timestamp in seconds (inclusive), 0 means now"	minimum(0)	default(0)
+//	@Param			limit						query		int													false	"The maximum number of records"								minimum(1)	maximum(1000)	default(100)
+//	@Success		200							{object}	api.BusinessError{data=[]model.TokenPriceInfo}		"Token prices"
+//	@Failure		600							{object}	api.BusinessError{data=string}						"Internal server error"
+//	@Router			/tokens/{address}/prices	[get]
+func (controller *Controller) getTokenPrices(c *gin.Context) (any, error) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		return nil, api.ErrValidationStrf("Invalid hex address %v", address)
+	}
+
+	var input model.HistoryRequest
+	if err := c.ShouldBind(&input); err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	return controller.services.Token.ListPrices(common.HexToAddress(address).String(), input)
+}
+
+// getReferral returns the referral info of specified user.
+//
+//	@Summary		Get referral
+//	@Description	Get the referrer and number of referees of specified user.
+//	@Tags			Referral
+//	@Accept			json
+//	@Produce		json
+//	@Param			address				path		string											true	"User address"
+//	@Success		200					{object}	api.BusinessError{data=model.ReferralInfo}		"Referral info"
+//	@Failure		600					{object}	api.BusinessError{data=string}					"Internal server error"
+//	@Router			/referrals/{address}	[get]
+func (controller *Controller) getReferral(c *gin.Context) (any, error) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		return nil, api.ErrValidationStrf("Invalid hex address %v", address)
+	}
+
+	info := model.ReferralInfo{
+		Address: strings.ToLower(address),
+	}
+
+	referral, err := controller.services.Referral.Get(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if referral != nil {
+		info.Referrer = referral.Referrer
+	}
+
+	if info.Referees, err = controller.services.Referral.CountReferees(address); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// bindReferrer binds the referrer for a user.
+//
+//	@Summary		Bind referrer
+//	@Description	Bind the referrer for a user, which is allowed only once. The user should sign the EIP-191 message
+//	@Description	"Bind referrer {referrer} for {address} before {deadline}" with both addresses in lowercase hex, and
+//	@Description	deadline is the unix timestamp in seconds that signature expires.
+//	@Tags			Referral
+//	@Accept			json
+//	@Produce		json
+//	@Param			request		body		model.BindReferrerRequest				true	"Referral binding request"
+//	@Success		200			{object}	api.BusinessError{data=model.Referral}	"Bound referral"
+//	@Failure		600			{object}	api.BusinessError{data=string}			"Internal server error"
+//	@Router			/referrals	[post]
+func (controller *Controller) bindReferrer(c *gin.Context) (any, error) {
+	var input model.BindReferrerRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	if !common.IsHexAddress(input.Address) {
+		return nil, api.ErrValidationStrf("Invalid hex address of user %v", input.Address)
+	}
+
+	if !common.IsHexAddress(input.Referrer) {
+		return nil, api.ErrValidationStrf("Invalid hex address of referrer %v", input.Referrer)
+	}
+
+	now := time.Now()
+	if deadline := time.Unix(input.Deadline, 0); deadline.Before(now) {
+		return nil, api.ErrValidationStr("Signature expired")
+	} else if deadline.Sub(now) > controller.signature.MaxValidity {
+		return nil, api.ErrValidationStrf("Deadline exceeds the maximum validity %v", controller.signature.MaxValidity)
+	}
+
+	message := referralMessage(input.Address, input.Referrer, input.Deadline)
+	signer, err := recoverPersonalSigner(message, input.Signature)
+	if err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	if signer != common.HexToAddress(input.Address) {
+		return nil, api.ErrValidationStr("Signature not signed by user")
+	}
+
+	return controller.services.Referral.Bind(input.Address, input.Referrer)
+}
+
+// bindReferrerSigned binds the referrer for the signer of EIP-712 signed request.
+//
+//	@Summary		Bind referrer by signed request
+//	@Description	Bind the referrer for the request signer, which is allowed only once. Request should be signed in EIP-712
+//	@Description	typed data Request(string method,string path,bytes body,uint256 nonce,uint256 deadline), and the signer,
+//	@Description	nonce, deadline and signature are specified in headers X-Signer, X-Nonce, X-Deadline and X-Signature.
+//	@Description	Note, path is the public URL path without query as requested, e.g. /points/api/actions/referral.
+//	@Tags			Referral
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Signer			header		string									true	"Signer address"
+//	@Param			X-Nonce				header		integer									true	"Unused nonce of signer"
+//	@Param			X-Deadline			header		integer									true	"Unix timestamp in seconds that request expires"
+//	@Param			X-Signature			header		string									true	"EIP-712 signature in hex"
+//	@Param			request				body		model.BindReferrerActionRequest			true	"Referral binding request"
+//	@Success		200					{object}	api.BusinessError{data=model.Referral}	"Bound referral"
+//	@Failure		600					{object}	api.BusinessError{data=string}			"Internal server error"
+//	@Router			/actions/referral	[post]
+func (controller *Controller) bindReferrerSigned(c *gin.Context) (any, error) {
+	var input model.BindReferrerActionRequest
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	if !common.IsHexAddress(input.Referrer) {
+		return nil, api.ErrValidationStrf("Invalid hex address of referrer %v", input.Referrer)
+	}
+
+	return controller.services.Referral.Bind(MustGetSigner(c).String(), input.Referrer)
+}
+
+// getUserProof returns the Merkle proof of airdrop for specified user.
+//
+//	@Summary		Get Merkle proof
+//	@Description	Get the airdrop amount and Merkle proof of specified user, which could be verified by OpenZeppelin's MerkleProof.
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@Param			address					path		string											true	"User address"
+//	@Param			season					query		string											false	"Season id, the latest season by default"
+//	@Success		200						{object}	api.BusinessError{data=model.MerkleProofInfo}	"Merkle proof"
+//	@Failure		600						{object}	api.BusinessError{data=string}					"Internal server error"
+//	@Router			/users/{address}/proof	[get]
+func (controller *Controller) getUserProof(c *gin.Context) (any, error) {
+	address := c.Param("address")
+	if !common.IsHexAddress(address) {
+		return nil, api.ErrValidationStrf("Invalid hex address %v", address)
+	}
+
+	var input model.MerkleProofRequest
+
+	if err := c.ShouldBind(&input); err != nil {
+		return nil, api.ErrValidation(err)
+	}
+
+	return controller.services.Merkle.GetProof(input.Season, address)
+}
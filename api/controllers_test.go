@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+	"github.com/mcuadros/go-defaults"
+	"github.com/v3-Swampy/points-service/migration"
+	"github.com/v3-Swampy/points-service/model"
+	"github.com/v3-Swampy/points-service/service"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestController(t *testing.T) *Controller {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	if _, err = migration.NewDefaultMigrator(db).Up(0); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	s := store.NewStore(db)
+	t.Cleanup(func() { s.Close() })
+
+	var config SignatureConfig
+	defaults.SetDefaults(&config)
+
+	return NewController(service.Services{Referral: service.NewReferralService(s)}, config)
+}
+
+// newBindReferrerContext creates a gin context to bind referrer, which is signed by the given key.
+func newBindReferrerContext(t *testing.T, input model.BindReferrerRequest, signer []byte) *gin.Context {
+	t.Helper()
+
+	key, err := crypto.ToECDSA(signer)
+	if err != nil {
+		t.Fatalf("Failed to load key: %v", err)
+	}
+
+	message := referralMessage(input.Address, input.Referrer, input.Deadline)
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	if err != nil {
+		t.Fatalf("Failed to sign message: %v", err)
+	}
+
+	input.Signature = hexutil.Encode(sig)
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/referrals", strings.NewReader(string(body)))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	return c
+}
+
+func TestBindReferrer(t *testing.T) {
+	controller := newTestController(t)
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	userKey := crypto.FromECDSA(key)
+	input := model.BindReferrerRequest{
+		Address:  crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		Referrer: "0x0000000000000000000000000000000000000001",
+		Deadline: time.Now().Add(time.Minute).Unix(),
+	}
+
+	// expired
+	expired := input
+	expired.Deadline = time.Now().Add(-time.Second).Unix()
+	if _, err := controller.bindReferrer(newBindReferrerContext(t, expired, userKey)); err == nil {
+		t.Fatal("Expected error to bind referrer with expired signature")
+	}
+
+	// too long validity
+	longLived := input
+	longLived.Deadline = time.Now().Add(time.Hour).Unix()
+	if _, err := controller.bindReferrer(newBindReferrerContext(t, longLived, userKey)); err == nil {
+		t.Fatal("Expected error to bind referrer with deadline beyond maximum validity")
+	}
+
+	// signed by others
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	if _, err := controller.bindReferrer(newBindReferrerContext(t, input, crypto.FromECDSA(other))); err == nil {
+		t.Fatal("Expected error to bind referrer with signature of others")
+	}
+
+	// bound
+	result, err := controller.bindReferrer(newBindReferrerContext(t, input, userKey))
+	if err != nil {
+		t.Fatalf("Failed to bind referrer: %v", err)
+	}
+
+	if referral := result.(*model.Referral); referral.Address != strings.ToLower(input.Address) {
+		t.Fatalf("Expected referral of %v, got %v", input.Address, referral.Address)
+	}
+
+	// replayed
+	if _, err := controller.bindReferrer(newBindReferrerContext(t, input, userKey)); err == nil {
+		t.Fatal("Expected error to bind referrer again")
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/v3-Swampy/points-service/service"
+	"github.com/v3-Swampy/points-service/sync/parsing"
+)
+
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusUnavailable = "unavailable"
+)
+
+// HealthConfig is the configurations of readiness check.
+type HealthConfig struct {
+	// readiness fails if the last processed snapshot is older than this, note snapshots are hourly
+	MaxSnapshotDelay time.Duration `default:"3h"`
+	// readiness fails if any pipeline stage keeps retrying longer than this
+	MaxRetryDuration time.Duration `default:"5m"`
+}
+
+// ComponentHealth is the health status of a component, e.g. database or pipeline stage.
+type ComponentHealth struct {
+	Status string `json:"status"`
+	Detail any    `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthResponse is the response of health check, which is ok only if all components are ok.
+type HealthResponse struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components,omitempty"`
+}
+
+type healthChecker struct {
+	config HealthConfig
+	cfg    *service.ConfigService
+}
+
+// live reports that process is running and able to serve requests.
+func (checker *healthChecker) live(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthResponse{Status: HealthStatusOK})
+}
+
+// ready reports whether database is reachable, the last processed snapshot is recent and pipeline stages are not
+// stuck in retry loops.
+func (checker *healthChecker) ready(c *gin.Context) {
+	now := time.Now()
+
+	components := map[string]ComponentHealth{
+		"database": checker.checkDatabase(),
+		"snapshot": checker.checkSnapshot(now),
+	}
+
+	for _, v := range parsing.PipelineStatus() {
+		components[v.Stage] = checker.checkStage(v, now)
+	}
+
+	resp := HealthResponse{Status: HealthStatusOK, Components: components}
+	for _, v := range components {
+		if v.Status != HealthStatusOK {
+			resp.Status = HealthStatusUnavailable
+		}
+	}
+
+	if resp.Status == HealthStatusOK {
+		c.JSON(http.StatusOK, resp)
+	} else {
+		c.JSON(http.StatusServiceUnavailable, resp)
+	}
+}
+
+func (checker *healthChecker) checkDatabase() ComponentHealth {
+	if err := checker.cfg.Ping(); err != nil {
+		return ComponentHealth{Status: HealthStatusUnavailable, Error: err.Error()}
+	}
+
+	return ComponentHealth{Status: HealthStatusOK}
+}
+
+func (checker *healthChecker) checkSnapshot(now time.Time) ComponentHealth {
+	timestamp, err := checker.cfg.GetLastStatPointsTime()
+	if err != nil {
+		return ComponentHealth{Status: HealthStatusUnavailable, Error: err.Error()}
+	}
+
+	if timestamp == 0 {
+		return ComponentHealth{Status: HealthStatusUnavailable, Error: "No snapshot processed yet"}
+	}
+
+	delay := now.Sub(time.Unix(timestamp, 0)).Truncate(time.Second)
+	detail := gin.H{
+		"lastTimestamp": timestamp,
+		"delay":         delay.String(),
+	}
+
+	if delay > checker.config.MaxSnapshotDelay {
+		return ComponentHealth{
+			Status: HealthStatusUnavailable,
+			Detail: detail,
+			Error:  fmt.Sprintf("Last snapshot delayed more than %v", checker.config.MaxSnapshotDelay),
+		}
+	}
+
+	return ComponentHealth{Status: HealthStatusOK, Detail: detail}
+}
+
+func (checker *healthChecker) checkStage(status parsing.StageStatus, now time.Time) ComponentHealth {
+	if retrying := status.RetryingFor(now); retrying > checker.config.MaxRetryDuration {
+		return ComponentHealth{
+			Status: HealthStatusUnavailable,
+			Detail: status,
+			Error:  fmt.Sprintf("Retrying for %v", retrying.Truncate(time.Second)),
+		}
+	}
+
+	return ComponentHealth{Status: HealthStatusOK, Detail: status}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rcrowley/go-metrics"
+)
+
+const metricsNamespace = "points_service"
+
+var metricsQuantiles = []float64{0.5, 0.75, 0.95, 0.99}
+
+// exportMetrics exposes metrics in default registry, which is configured by go-conflux-util, in Prometheus text
+// format. Note, metrics are empty unless enabled in configurations.
+func exportMetrics(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	writePrometheus(c.Writer, metrics.DefaultRegistry)
+}
+
+// writePrometheus writes all metrics of registry in Prometheus text format, sorted by name. Timers are exported
+// in seconds.
+func writePrometheus(w io.Writer, registry metrics.Registry) {
+	all := make(map[string]any)
+	registry.Each(func(name string, metric any) {
+		all[name] = metric
+	})
+
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		promName := prometheusName(name)
+
+		switch metric := all[name].(type) {
+		case metrics.Counter:
+			writeSample(w, promName, "counter", metric.Snapshot().Count())
+		case metrics.Gauge:
+			writeSample(w, promName, "gauge", metric.Snapshot().Value())
+		case metrics.GaugeFloat64:
+			writeSample(w, promName, "gauge", metric.Snapshot().Value())
+		case metrics.Meter:
+			writeSample(w, promName+"_total", "counter", metric.Snapshot().Count())
+		case metrics.Histogram:
+			snapshot := metric.Snapshot()
+			writeSummary(w, promName, snapshot.Percentiles(metricsQuantiles), float64(snapshot.Sum()), snapshot.Count())
+		case metrics.Timer:
+			snapshot := metric.Snapshot()
+			quantiles := snapshot.Percentiles(metricsQuantiles)
+			for i := range quantiles {
+				quantiles[i] /= float64(time.Second)
+			}
+			writeSummary(w, promName+"_seconds", quantiles, float64(snapshot.Sum())/float64(time.Second), snapshot.Count())
+		case interface{ Value() float64 }: // e.g. percentage of go-conflux-util
+			writeSample(w, promName, "gauge", metric.Value())
+		}
+	}
+}
+
+func writeSample(w io.Writer, name, metricType string, value any) {
+	fmt.Fprintf(w, "# TYPE %v %v\n", name, metricType)
+	fmt.Fprintf(w, "%v %v\n", name, value)
+}
+
+func writeSummary(w io.Writer, name string, quantiles []float64, sum float64, count int64) {
+	fmt.Fprintf(w, "# TYPE %v summary\n", name)
+
+	for i, q := range metricsQuantiles {
+		fmt.Fprintf(w, "%v{quantile=\"%v\"} %v\n", name, q, quantiles[i])
+	}
+
+	fmt.Fprintf(w, "%v_sum %v\n", name, sum)
+	fmt.Fprintf(w, "%v_count %v\n", name, count)
+}
+
+// prometheusName converts metric name, e.g. sync/poller/lag, into Prometheus format, e.g.
+// points_service_sync_poller_lag.
+func prometheusName(name string) string {
+	var builder strings.Builder
+	builder.WriteString(metricsNamespace)
+	builder.WriteByte('_')
+
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			builder.WriteRune(r)
+		} else {
+			builder.WriteByte('_')
+		}
+	}
+
+	return builder.String()
+}
@@ -16,8 +16,14 @@ func MustServeFromViper(services service.Services) {
 	var config api.Config
 	viper.MustUnmarshalKey("api", &config)
 
+	var authConfig AuthConfig
+	viper.MustUnmarshalKey("auth", &authConfig)
+
+	var healthConfig HealthConfig
+	viper.MustUnmarshalKey("health", &healthConfig)
+
 	api.MustServe(config, func(router *gin.Engine) {
-		Routes(router, services)
+		Routes(router, services, authConfig, healthConfig)
 	})
 }
 
@@ -25,14 +31,39 @@ func MustServeFromViper(services service.Services) {
 //	@version		1.0
 //	@description	Use any http client to fetch data from the Points Service
 
-func Routes(router *gin.Engine, services service.Services) {
+func Routes(router *gin.Engine, services service.Services, authConfig AuthConfig, healthConfig HealthConfig) {
 	docs.SwaggerInfo.BasePath = "/points/api"
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/metrics", exportMetrics)
+
+	// health check for load balancer
+	health := &healthChecker{healthConfig, services.Config}
+	router.GET("/health/live", health.live)
+	router.GET("/health/ready", health.ready)
 
-	controller := NewController(services)
+	controller := NewController(services, authConfig.Signature)
 
 	router.GET("/api/users", middleware.Wrap(controller.listUsers))
+	router.GET("/api/users/:address/proof", middleware.Wrap(controller.getUserProof))
 	router.GET("/api/pools", middleware.Wrap(controller.listPools))
+	router.GET("/api/pools/:address", middleware.Wrap(controller.getPool))
+	router.GET("/api/pools/:address/tvl-history", middleware.Wrap(controller.getPoolTvlHistory))
+	router.GET("/api/pools/:address/metrics", middleware.Wrap(controller.getPoolMetrics))
+	router.GET("/api/tokens", middleware.Wrap(controller.listTokens))
+	router.GET("/api/tokens/:address/prices", middleware.Wrap(controller.getTokenPrices))
+	router.GET("/api/referrals/:address", middleware.Wrap(controller.getReferral))
+	router.POST("/api/referrals", middleware.Wrap(controller.bindReferrer))
+
+	// user actions authenticated by EIP-712 signature
+	actions := router.Group("/api/actions", SignatureRequired(authConfig.Signature, services.SignedRequest))
+	actions.POST("/referral", middleware.Wrap(controller.bindReferrerSigned))
+
+	// admin operations authenticated by API key or EIP-712 signature of allowlisted signers
+	admin := router.Group("/api/admin", AdminRequired(authConfig, services.SignedRequest))
+	admin.POST("/pools/weight", middleware.Wrap(controller.upsertPoolWeight))
+	admin.POST("/users", middleware.Wrap(controller.insertUserPoints))
+	admin.POST("/users/points", middleware.Wrap(controller.updateUserPoints))
+	admin.POST("/tokens/override", middleware.Wrap(controller.overrideToken))
 
 	logrus.Info("Service started")
 }
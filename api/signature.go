@@ -0,0 +1,61 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+)
+
+// referralMessage returns the EIP-191 message that referee should sign to bind the referrer, which expires after
+// deadline in unix timestamp of seconds.
+func referralMessage(address, referrer string, deadline int64) string {
+	return fmt.Sprintf("Bind referrer %v for %v before %v", strings.ToLower(referrer), strings.ToLower(address), deadline)
+}
+
+// decodeSignature decodes the hex encoded 65 bytes signature [R || S || V], where V could be 0/1 or 27/28.
+func decodeSignature(signature string) ([]byte, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Invalid hex signature")
+	}
+
+	if len(sig) != crypto.SignatureLength {
+		return nil, errors.Errorf("Invalid signature length %v", len(sig))
+	}
+
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	return sig, nil
+}
+
+// recoverSigner recovers the signer address from given hash and hex encoded signature.
+func recoverSigner(hash []byte, signature string) (common.Address, error) {
+	sig, err := decodeSignature(signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	pubKey, err := crypto.Ecrecover(hash, sig)
+	if err != nil {
+		return common.Address{}, errors.WithMessage(err, "Failed to recover public key")
+	}
+
+	pub, err := crypto.UnmarshalPubkey(pubKey)
+	if err != nil {
+		return common.Address{}, errors.WithMessage(err, "Invalid public key recovered")
+	}
+
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// recoverPersonalSigner recovers the signer address of an EIP-191 (personal_sign) message.
+func recoverPersonalSigner(message string, signature string) (common.Address, error) {
+	return recoverSigner(accounts.TextHash([]byte(message)), signature)
+}
@@ -0,0 +1,287 @@
+package blockchain
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/openweb3/web3go"
+	"github.com/openweb3/web3go/types"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Block resolvers that could be configured in fallback order.
+const (
+	BlockResolverScan = "scan" // getblocknobytime of scan open API
+	BlockResolverRPC  = "rpc"  // binary search on block timestamps via eth_getBlockByNumber
+)
+
+// BlockResolverConfig is the configurations to resolve block number by time.
+type BlockResolverConfig struct {
+	// block resolvers in fallback order, defaults to scan and then rpc
+	Order []string
+	// maximum number of block timestamps cached in memory for rpc resolver
+	CacheSize int `default:"100000"`
+	// blocks behind the latest block that will not be reorganized, whose timestamps could be cached
+	Confirmations uint64 `default:"100"`
+}
+
+// BlockResolver resolves block number by time.
+type BlockResolver interface {
+	// GetBlockNumberByTime returns the first block at or after the given timestamp if after is true, or the last
+	// block at or before the given timestamp if after is false.
+	GetBlockNumberByTime(timestampSecs int64, after bool) (uint64, error)
+}
+
+// RpcBlockResolver resolves block number by binary search on block timestamps via eth_getBlockByNumber, so that
+// it does not depend on the scan service. Timestamps of confirmed blocks are cached in memory, and the nearest
+// cached blocks around the timestamp narrow the search range, e.g. shared by searches of adjacent snapshots.
+type RpcBlockResolver struct {
+	client        *web3go.Client
+	confirmations uint64
+	cache         *blockTimeCache
+}
+
+var _ BlockResolver = (*RpcBlockResolver)(nil)
+
+func NewRpcBlockResolver(client *web3go.Client, config BlockResolverConfig) *RpcBlockResolver {
+	size := config.CacheSize
+	if size <= 0 {
+		size = 100000
+	}
+
+	return &RpcBlockResolver{
+		client:        client,
+		confirmations: config.Confirmations,
+		cache:         &blockTimeCache{size: size},
+	}
+}
+
+func (resolver *RpcBlockResolver) GetBlockNumberByTime(timestampSecs int64, after bool) (uint64, error) {
+	if timestampSecs < 0 {
+		return 0, errors.Errorf("Invalid timestamp %v", timestampSecs)
+	}
+
+	ts := uint64(timestampSecs)
+
+	latest, err := resolver.client.Eth.BlockByNumber(types.LatestBlockNumber, false)
+	if err != nil {
+		return 0, errors.WithMessage(err, "Failed to get latest block")
+	}
+
+	if latest == nil {
+		return 0, errors.New("Latest block not found")
+	}
+
+	latestBN := latest.Number.Uint64()
+
+	if after {
+		if latest.Timestamp < ts {
+			return 0, errors.Errorf("No block found after timestamp %v, latest block timestamp = %v", ts, latest.Timestamp)
+		}
+
+		// find the first block whose timestamp >= ts
+		return resolver.search(latestBN, func(blockTs uint64) bool { return blockTs >= ts })
+	}
+
+	if latest.Timestamp <= ts {
+		return latestBN, nil
+	}
+
+	// find the first block whose timestamp > ts, and then the last block whose timestamp <= ts is the previous one
+	bn, err := resolver.search(latestBN, func(blockTs uint64) bool { return blockTs > ts })
+	if err != nil {
+		return 0, err
+	}
+
+	if bn == 0 {
+		return 0, errors.Errorf("No block found before timestamp %v", ts)
+	}
+
+	return bn - 1, nil
+}
+
+// search returns the first block in range [0, latestBN] that matches the given predicate on block timestamp, which
+// is monotonic and true for the latest block. The search range is narrowed by the nearest cached blocks at first.
+func (resolver *RpcBlockResolver) search(latestBN uint64, match func(blockTs uint64) bool) (uint64, error) {
+	low, high := resolver.cache.bounds(latestBN, match)
+
+	for low < high {
+		mid := low + (high-low)/2
+
+		midTs, err := resolver.blockTimestamp(mid, latestBN)
+		if err != nil {
+			return 0, err
+		}
+
+		if match(midTs) {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+
+	return low, nil
+}
+
+// blockTimestamp returns the timestamp of block, which is cached if confirmed.
+func (resolver *RpcBlockResolver) blockTimestamp(bn, latestBN uint64) (uint64, error) {
+	if ts, ok := resolver.cache.get(bn); ok {
+		return ts, nil
+	}
+
+	block, err := resolver.client.Eth.BlockByNumber(types.BlockNumber(bn), false)
+	if err != nil {
+		return 0, errors.WithMessagef(err, "Failed to get block %v", bn)
+	}
+
+	if block == nil {
+		return 0, errors.Errorf("Block %v not found", bn)
+	}
+
+	if bn+resolver.confirmations <= latestBN {
+		resolver.cache.add(bn, block.Timestamp)
+	}
+
+	return block.Timestamp, nil
+}
+
+// blockTime is the timestamp of block.
+type blockTime struct {
+	number    uint64
+	timestamp uint64
+}
+
+// blockTimeCache caches timestamps of confirmed blocks in order of block number, so as to look up the nearest
+// cached blocks around a timestamp. Once full, the cached block farthest from the added one is evicted.
+type blockTimeCache struct {
+	mu     sync.Mutex
+	size   int
+	blocks []blockTime // in ascending order of block number
+}
+
+func (cache *blockTimeCache) get(bn uint64) (uint64, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	i, ok := cache.index(bn)
+	if !ok {
+		return 0, false
+	}
+
+	return cache.blocks[i].timestamp, true
+}
+
+func (cache *blockTimeCache) add(bn, ts uint64) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	i, ok := cache.index(bn)
+	if ok {
+		return
+	}
+
+	cache.blocks = slices.Insert(cache.blocks, i, blockTime{bn, ts})
+
+	if len(cache.blocks) > cache.size {
+		if first, last := cache.blocks[0].number, cache.blocks[len(cache.blocks)-1].number; bn-first > last-bn {
+			cache.blocks = cache.blocks[1:]
+		} else {
+			cache.blocks = cache.blocks[:len(cache.blocks)-1]
+		}
+	}
+}
+
+// index returns the index of block if cached, otherwise the index to insert.
+func (cache *blockTimeCache) index(bn uint64) (int, bool) {
+	return slices.BinarySearchFunc(cache.blocks, bn, func(v blockTime, target uint64) int {
+		return cmp.Compare(v.number, target)
+	})
+}
+
+// bounds returns the search range in [0, latestBN] for the first block that matches the given predicate on block
+// timestamp, which is narrowed by the last cached block that not matched and the first cached block that matched.
+func (cache *blockTimeCache) bounds(latestBN uint64, match func(blockTs uint64) bool) (low, high uint64) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	high = latestBN
+
+	// timestamps are monotonic in order of block number
+	i := sort.Search(len(cache.blocks), func(i int) bool { return match(cache.blocks[i].timestamp) })
+
+	if i < len(cache.blocks) {
+		high = min(high, cache.blocks[i].number)
+	}
+
+	if i > 0 {
+		low = min(cache.blocks[i-1].number+1, high)
+	}
+
+	return low, high
+}
+
+// FallbackBlockResolver resolves block number by resolvers in order until succeeded.
+type FallbackBlockResolver struct {
+	names     []string
+	resolvers []BlockResolver
+}
+
+var _ BlockResolver = (*FallbackBlockResolver)(nil)
+
+// NewFallbackBlockResolver creates a resolver with named resolvers in the configured order, which defaults to
+// scan and then rpc. Note, resolvers not available, e.g. nil, are ignored unless explicitly configured.
+func NewFallbackBlockResolver(config BlockResolverConfig, resolvers map[string]BlockResolver) (*FallbackBlockResolver, error) {
+	order := config.Order
+	if len(order) == 0 {
+		for _, name := range []string{BlockResolverScan, BlockResolverRPC} {
+			if resolvers[name] != nil {
+				order = append(order, name)
+			}
+		}
+	}
+
+	var result FallbackBlockResolver
+	for _, name := range order {
+		resolver := resolvers[name]
+		if resolver == nil {
+			return nil, errors.Errorf("Block resolver %v not available", name)
+		}
+
+		result.names = append(result.names, name)
+		result.resolvers = append(result.resolvers, resolver)
+	}
+
+	if len(result.resolvers) == 0 {
+		return nil, errors.New("Block resolver not specified")
+	}
+
+	return &result, nil
+}
+
+func (resolver *FallbackBlockResolver) GetBlockNumberByTime(timestampSecs int64, after bool) (uint64, error) {
+	var lastErr error
+
+	for i, v := range resolver.resolvers {
+		bn, err := v.GetBlockNumberByTime(timestampSecs, after)
+		if err == nil && bn > 0 {
+			return bn, nil
+		}
+
+		if err == nil {
+			err = errors.Errorf("0 returned by timestamp %v", timestampSecs)
+		}
+
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"resolver": resolver.names[i],
+			"ts":       timestampSecs,
+			"after":    after,
+		}).Warn("Failed to resolve block number by time")
+
+		lastErr = err
+	}
+
+	return 0, errors.WithMessage(lastErr, "All block resolvers failed")
+}
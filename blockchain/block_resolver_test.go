@@ -0,0 +1,333 @@
+package blockchain
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/openweb3/web3go"
+	"github.com/pkg/errors"
+)
+
+// fakeChain is a fake blockchain RPC that serves eth_getBlockByNumber of blocks with the given timestamps, and
+// counts requests of blocks other than the latest one.
+type fakeChain struct {
+	timestamps []uint64 // block number => timestamp
+	requests   atomic.Int32
+}
+
+// newFakeChain creates blocks from 0 to latest, where every 3 blocks share the same timestamp starting from 1000,
+// i.e. timestamps 1000, 1000, 1000, 1003, 1003, 1003, ...
+func newFakeChain(t *testing.T, latest uint64) (*fakeChain, *web3go.Client) {
+	t.Helper()
+
+	chain := &fakeChain{}
+	for bn := uint64(0); bn <= latest; bn++ {
+		chain.timestamps = append(chain.timestamps, 1000+bn-bn%3)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []any           `json:"params"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		if result, err := chain.handle(req.Method, req.Params); err != nil {
+			resp["error"] = map[string]any{"code": -32000, "message": err.Error()}
+		} else {
+			resp["result"] = result
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := web3go.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	return chain, client
+}
+
+func (chain *fakeChain) handle(method string, params []any) (any, error) {
+	if method != "eth_getBlockByNumber" || len(params) == 0 {
+		return nil, errors.Errorf("method %v not supported", method)
+	}
+
+	bn := uint64(len(chain.timestamps) - 1)
+	if tag := params[0].(string); tag == "earliest" {
+		bn = 0
+		chain.requests.Add(1)
+	} else if tag != "latest" {
+		parsed, err := strconv.ParseUint(strings.TrimPrefix(tag, "0x"), 16, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if parsed >= uint64(len(chain.timestamps)) {
+			return nil, nil
+		}
+
+		bn = parsed
+		chain.requests.Add(1)
+	}
+
+	return map[string]any{
+		"number":       hexutil.EncodeUint64(bn),
+		"timestamp":    hexutil.EncodeUint64(chain.timestamps[bn]),
+		"difficulty":   "0x0",
+		"transactions": []any{},
+	}, nil
+}
+
+// expected returns the block number by linear scan on timestamps, and false if not found.
+func (chain *fakeChain) expected(ts uint64, after bool) (uint64, bool) {
+	if after {
+		for bn, v := range chain.timestamps {
+			if v >= ts {
+				return uint64(bn), true
+			}
+		}
+
+		return 0, false
+	}
+
+	for bn := len(chain.timestamps) - 1; bn >= 0; bn-- {
+		if chain.timestamps[bn] <= ts {
+			return uint64(bn), true
+		}
+	}
+
+	return 0, false
+}
+
+func TestRpcBlockResolverGetBlockNumberByTime(t *testing.T) {
+	chain, client := newFakeChain(t, 1000) // latest block 1000 at timestamp 1999
+
+	for _, v := range []struct {
+		name     string
+		ts       int64
+		after    bool
+		expected uint64
+		err      bool
+	}{
+		{"after: negative", -1, true, 0, true},
+		{"after: before genesis", 999, true, 0, false},
+		{"after: at genesis", 1000, true, 0, false},
+		{"after: at shared timestamp", 1501, true, 501, false},
+		{"after: in gap", 1502, true, 504, false},
+		{"after: at latest", 1999, true, 999, false},
+		{"after: beyond latest", 2000, true, 0, true},
+		{"before: negative", -1, false, 0, true},
+		{"before: before genesis", 999, false, 0, true},
+		{"before: at genesis", 1000, false, 2, false},
+		{"before: at shared timestamp", 1501, false, 503, false},
+		{"before: in gap", 1502, false, 503, false},
+		{"before: at latest", 1999, false, 1000, false},
+		{"before: beyond latest", 2000, false, 1000, false},
+	} {
+		resolver := NewRpcBlockResolver(client, BlockResolverConfig{Confirmations: 10})
+
+		bn, err := resolver.GetBlockNumberByTime(v.ts, v.after)
+		if (err != nil) != v.err {
+			t.Fatalf("%v: unexpected error %v", v.name, err)
+		}
+
+		if bn != v.expected {
+			t.Fatalf("%v: expected block %v, got %v", v.name, v.expected, bn)
+		}
+	}
+
+	// searches narrowed by cached blocks agree with linear scan
+	resolver := NewRpcBlockResolver(client, BlockResolverConfig{Confirmations: 10})
+	for ts := uint64(990); ts <= 2010; ts += 7 {
+		for _, after := range []bool{true, false} {
+			expected, ok := chain.expected(ts, after)
+
+			bn, err := resolver.GetBlockNumberByTime(int64(ts), after)
+			if (err == nil) != ok || bn != expected {
+				t.Fatalf("Timestamp %v after %v: expected block %v (found %v), got %v, err = %v", ts, after, expected, ok, bn, err)
+			}
+		}
+	}
+}
+
+func TestRpcBlockResolverCache(t *testing.T) {
+	chain, client := newFakeChain(t, 1000)
+	resolver := NewRpcBlockResolver(client, BlockResolverConfig{Confirmations: 10})
+
+	// only blocks confirmed by 10 blocks are cached
+	if _, err := resolver.GetBlockNumberByTime(1999, true); err != nil {
+		t.Fatalf("Failed to resolve block: %v", err)
+	}
+
+	if len(resolver.cache.blocks) == 0 {
+		t.Fatal("Confirmed blocks not cached")
+	}
+
+	for _, v := range resolver.cache.blocks {
+		if v.number > 990 {
+			t.Fatalf("Block %v cached, confirmations not respected", v.number)
+		}
+	}
+
+	// unconfirmed blocks are requested again
+	chain.requests.Store(0)
+	if _, err := resolver.GetBlockNumberByTime(1999, true); err != nil {
+		t.Fatalf("Failed to resolve block: %v", err)
+	}
+
+	if chain.requests.Load() == 0 {
+		t.Fatal("Unconfirmed blocks should not be cached")
+	}
+
+	// confirmed blocks are served by cache
+	if _, err := resolver.GetBlockNumberByTime(1501, true); err != nil {
+		t.Fatalf("Failed to resolve block: %v", err)
+	}
+
+	chain.requests.Store(0)
+	if bn, err := resolver.GetBlockNumberByTime(1501, true); err != nil || bn != 501 {
+		t.Fatalf("Expected block 501, got %v, err = %v", bn, err)
+	}
+
+	if requests := chain.requests.Load(); requests != 0 {
+		t.Fatalf("Expected resolved by cache, got %v requests", requests)
+	}
+}
+
+func TestRpcBlockResolverNarrowSearch(t *testing.T) {
+	chain, client := newFakeChain(t, 100000)
+	resolver := NewRpcBlockResolver(client, BlockResolverConfig{})
+
+	// cached neighbors, e.g. resolved for adjacent snapshots
+	for _, bn := range []uint64{48000, 48064} {
+		resolver.cache.add(bn, chain.timestamps[bn])
+	}
+
+	for _, after := range []bool{true, false} {
+		chain.requests.Store(0)
+
+		bn, err := resolver.GetBlockNumberByTime(int64(chain.timestamps[48031]), after)
+		if err != nil {
+			t.Fatalf("Failed to resolve block: %v", err)
+		}
+
+		if expected, _ := chain.expected(chain.timestamps[48031], after); bn != expected {
+			t.Fatalf("Expected block %v, got %v", expected, bn)
+		}
+
+		// binary search in range of 64 blocks rather than 100000 blocks
+		if requests := chain.requests.Load(); requests > 6 {
+			t.Fatalf("Search not narrowed by cached neighbors, got %v requests", requests)
+		}
+	}
+}
+
+func TestBlockTimeCacheEviction(t *testing.T) {
+	cache := &blockTimeCache{size: 3}
+	for _, bn := range []uint64{10, 20, 30, 40} {
+		cache.add(bn, bn)
+	}
+
+	// the farthest from the added block evicted
+	if _, ok := cache.get(10); ok {
+		t.Fatal("Block 10 should be evicted")
+	}
+
+	cache.add(5, 5)
+	if _, ok := cache.get(40); ok {
+		t.Fatal("Block 40 should be evicted")
+	}
+
+	for _, bn := range []uint64{5, 20, 30} {
+		if ts, ok := cache.get(bn); !ok || ts != bn {
+			t.Fatalf("Block %v should be cached", bn)
+		}
+	}
+}
+
+// fakeBlockResolver returns the configured block number or error, and records the number of calls.
+type fakeBlockResolver struct {
+	bn    uint64
+	err   error
+	calls int
+}
+
+func (resolver *fakeBlockResolver) GetBlockNumberByTime(timestampSecs int64, after bool) (uint64, error) {
+	resolver.calls++
+	return resolver.bn, resolver.err
+}
+
+func TestFallbackBlockResolver(t *testing.T) {
+	failed := errors.New("unavailable")
+
+	for _, v := range []struct {
+		name     string
+		order    []string
+		scan     *fakeBlockResolver // nil if not available
+		rpc      *fakeBlockResolver
+		expected uint64
+		calls    [2]int // calls of scan and rpc
+		err      bool
+	}{
+		{"default order", nil, &fakeBlockResolver{bn: 1}, &fakeBlockResolver{bn: 2}, 1, [2]int{1, 0}, false},
+		{"default order without scan", nil, nil, &fakeBlockResolver{bn: 2}, 2, [2]int{0, 1}, false},
+		{"configured order", []string{"rpc", "scan"}, &fakeBlockResolver{bn: 1}, &fakeBlockResolver{bn: 2}, 2, [2]int{0, 1}, false},
+		{"fallback on error", nil, &fakeBlockResolver{err: failed}, &fakeBlockResolver{bn: 2}, 2, [2]int{1, 1}, false},
+		{"fallback on zero", nil, &fakeBlockResolver{}, &fakeBlockResolver{bn: 2}, 2, [2]int{1, 1}, false},
+		{"all failed", nil, &fakeBlockResolver{err: failed}, &fakeBlockResolver{}, 0, [2]int{1, 1}, true},
+	} {
+		resolvers := map[string]BlockResolver{BlockResolverRPC: v.rpc}
+		if v.scan != nil {
+			resolvers[BlockResolverScan] = v.scan
+		}
+
+		resolver, err := NewFallbackBlockResolver(BlockResolverConfig{Order: v.order}, resolvers)
+		if err != nil {
+			t.Fatalf("%v: failed to create resolver: %v", v.name, err)
+		}
+
+		bn, err := resolver.GetBlockNumberByTime(1000, true)
+		if (err != nil) != v.err || bn != v.expected {
+			t.Fatalf("%v: expected block %v, got %v, err = %v", v.name, v.expected, bn, err)
+		}
+
+		var calls [2]int
+		if v.scan != nil {
+			calls[0] = v.scan.calls
+		}
+		calls[1] = v.rpc.calls
+
+		if calls != v.calls {
+			t.Fatalf("%v: expected calls %v, got %v", v.name, v.calls, calls)
+		}
+	}
+
+	// configured resolver not available
+	_, err := NewFallbackBlockResolver(BlockResolverConfig{Order: []string{"scan"}}, map[string]BlockResolver{
+		BlockResolverRPC: &fakeBlockResolver{},
+	})
+	if err == nil {
+		t.Fatal("Expected error if configured resolver not available")
+	}
+
+	if _, err = NewFallbackBlockResolver(BlockResolverConfig{}, nil); err == nil {
+		t.Fatal("Expected error if no resolver available")
+	}
+}
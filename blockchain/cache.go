@@ -1,24 +1,125 @@
 package blockchain
 
 import (
-	"sync"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// Namespaces of cached on-chain metadata in store.
+const (
+	CacheNamespaceToken = "token" // ERC20 name, symbol and decimals
+	CacheNamespacePair  = "pair"  // Swappi pair token0 and token1
+	CacheNamespacePool  = "pool"  // Vswap pool token0, token1 and fee
 )
 
-type cacheable[K any, V any] struct {
-	cache sync.Map
+// CacheConfig is the configurations of in-memory LRU cache for on-chain metadata.
+type CacheConfig struct {
+	Size int           `default:"10000"` // maximum number of entries of each namespace
+	TTL  time.Duration `default:"1h"`    // expiration in memory, so that invalidated entries in store will be reloaded
+}
+
+// CacheStore is the persistent store of cached on-chain metadata, e.g. database, which survives restarts and
+// could be invalidated explicitly.
+type CacheStore interface {
+	// Load returns the encoded value of key in namespace, or false if not found.
+	Load(namespace, key string) ([]byte, bool, error)
+
+	// Save saves the encoded value of key in namespace.
+	Save(namespace, key string, value []byte) error
+}
+
+// CacheOption is the option to create cache for on-chain metadata, where store is optional.
+type CacheOption struct {
+	Config CacheConfig
+	Store  CacheStore
+}
+
+type cacheKey interface {
+	comparable
+	fmt.Stringer
+}
+
+// cacheable is a layered cache, which caches values in an expirable LRU, backed by an optional store. Besides,
+// concurrent misses for the same key result in only one query.
+type cacheable[K cacheKey, V any] struct {
+	namespace string
+	memory    *expirable.LRU[K, V]
+	store     CacheStore
+	group     *singleflight.Group
+}
+
+func newCacheable[K cacheKey, V any](namespace string, option CacheOption) cacheable[K, V] {
+	size := option.Config.Size
+	if size <= 0 {
+		size = 10000
+	}
+
+	ttl := option.Config.TTL
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+
+	return cacheable[K, V]{
+		namespace: namespace,
+		memory:    expirable.NewLRU[K, V](size, nil, ttl),
+		store:     option.Store,
+		group:     new(singleflight.Group),
+	}
 }
 
 func (c *cacheable[K, V]) getOrQueryFunc(key K, queryFunc func(key K) (V, error)) (V, error) {
-	if cached, ok := c.cache.Load(key); ok {
-		return cached.(V), nil
+	if cached, ok := c.memory.Get(key); ok {
+		return cached, nil
+	}
+
+	val, err, _ := c.group.Do(key.String(), func() (any, error) {
+		return c.loadOrQuery(key, queryFunc)
+	})
+	if err != nil {
+		var empty V
+		return empty, err
+	}
+
+	c.memory.Add(key, val.(V))
+
+	return val.(V), nil
+}
+
+// loadOrQuery loads value from store if any, otherwise, queries value and saves it into store.
+func (c *cacheable[K, V]) loadOrQuery(key K, queryFunc func(key K) (V, error)) (val V, err error) {
+	if c.store == nil {
+		return queryFunc(key)
 	}
 
-	val, err := queryFunc(key)
+	encoded, ok, err := c.store.Load(c.namespace, key.String())
 	if err != nil {
+		return val, errors.WithMessagef(err, "Failed to load %v cache from store", c.namespace)
+	}
+
+	if ok {
+		if err = json.Unmarshal(encoded, &val); err != nil {
+			return val, errors.WithMessagef(err, "Failed to unmarshal %v cache", c.namespace)
+		}
+
+		return val, nil
+	}
+
+	if val, err = queryFunc(key); err != nil {
 		return val, err
 	}
 
-	c.cache.Store(key, val)
+	if encoded, err = json.Marshal(val); err != nil {
+		return val, errors.WithMessagef(err, "Failed to marshal %v cache", c.namespace)
+	}
+
+	if err = c.store.Save(c.namespace, key.String(), encoded); err != nil {
+		return val, errors.WithMessagef(err, "Failed to save %v cache into store", c.namespace)
+	}
 
 	return val, nil
 }
@@ -11,6 +11,10 @@ type Config struct {
 
 	Swappi SwappiConfig
 	Vswap  VswapConfig
+	Cache  CacheConfig
+
+	Multicall MulticallConfig
+	RateLimit RateLimitConfig
 }
 
 type SwappiConfig struct {
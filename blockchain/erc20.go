@@ -1,8 +1,15 @@
 package blockchain
 
 import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"github.com/v3-Swampy/points-service/blockchain/contract"
@@ -21,9 +28,10 @@ type ERC20 struct {
 	caller bind.ContractCaller
 }
 
-func NewERC20(caller bind.ContractCaller) *ERC20 {
+func NewERC20(caller bind.ContractCaller, cache CacheOption) *ERC20 {
 	return &ERC20{
-		caller: caller,
+		cacheable: newCacheable[common.Address, TokenInfo](CacheNamespaceToken, cache),
+		caller:    caller,
 	}
 }
 
@@ -43,11 +51,11 @@ func (erc20 *ERC20) GetTokenInfoForce(token common.Address) (TokenInfo, error) {
 	}
 
 	// retrieves name, symbol and decimals
-	if info.Name, err = caller.Name(nil); err != nil {
+	if info.Name, err = erc20.queryString(token, "name"); err != nil {
 		return TokenInfo{}, errors.WithMessage(err, "Failed to query token name")
 	}
 
-	if info.Symbol, err = caller.Symbol(nil); err != nil {
+	if info.Symbol, err = erc20.queryString(token, "symbol"); err != nil {
 		return TokenInfo{}, errors.WithMessage(err, "Failed to query token symbol")
 	}
 
@@ -58,16 +66,58 @@ func (erc20 *ERC20) GetTokenInfoForce(token common.Address) (TokenInfo, error) {
 	return info, nil
 }
 
-// GetBalance retrieves the token balance of specified account.
+// queryString queries the string value of given method without arguments, e.g. name or symbol. Note, some legacy
+// tokens (e.g. MKR) return bytes32 instead of string, and empty string returned if method not implemented, i.e.
+// call reverted or nothing returned.
+func (erc20 *ERC20) queryString(token common.Address, method string) (string, error) {
+	output, err := erc20.caller.CallContract(context.Background(), ethereum.CallMsg{
+		To:   &token,
+		Data: crypto.Keccak256([]byte(method + "()"))[:4],
+	}, nil)
+	if isCallReverted(err) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	value, err := decodeStringOrBytes32(output)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToValidUTF8(value, ""), nil
+}
+
+var stringArguments = abi.Arguments{{Type: abi.Type{T: abi.StringTy}}}
+
+func decodeStringOrBytes32(output []byte) (string, error) {
+	switch len(output) {
+	case 0:
+		return "", nil
+	case 32:
+		return string(bytes.TrimRight(output, "\x00")), nil
+	}
+
+	values, err := stringArguments.Unpack(output)
+	if err != nil {
+		return "", errors.WithMessage(err, "Failed to unpack string")
+	}
+
+	return values[0].(string), nil
+}
+
+// GetBalance retrieves the token balance of specified account, where token decimals are cached.
 func (erc20 *ERC20) GetBalance(opts *bind.CallOpts, token, account common.Address) (decimal.Decimal, error) {
-	caller, err := contract.NewERC20Caller(token, erc20.caller)
+	info, err := erc20.GetTokenInfo(token)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to create ERC20 caller")
+		return decimal.Zero, errors.WithMessage(err, "Failed to get token info")
 	}
 
-	decimals, err := caller.Decimals(opts)
+	caller, err := contract.NewERC20Caller(token, erc20.caller)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to get decimals")
+		return decimal.Zero, errors.WithMessage(err, "Failed to create ERC20 caller")
 	}
 
 	balance, err := caller.BalanceOf(opts, account)
@@ -75,5 +125,13 @@ func (erc20 *ERC20) GetBalance(opts *bind.CallOpts, token, account common.Addres
 		return decimal.Zero, errors.WithMessage(err, "Failed to get balance")
 	}
 
-	return decimal.NewFromBigInt(balance, -int32(decimals)), nil
+	return decimal.NewFromBigInt(balance, -int32(info.Decimals)), nil
+}
+
+// withCaller returns a copy of ERC20 with the given caller, which shares the same cache.
+func (erc20 *ERC20) withCaller(caller bind.ContractCaller) *ERC20 {
+	copied := *erc20
+	copied.caller = caller
+
+	return &copied
 }
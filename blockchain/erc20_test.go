@@ -0,0 +1,54 @@
+package blockchain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+// fakeCaller returns the error of contract call if any, otherwise the output.
+type fakeCaller struct {
+	output []byte
+	err    error
+}
+
+func (c *fakeCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x1}, nil
+}
+
+func (c *fakeCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return c.output, c.err
+}
+
+func TestERC20QueryString(t *testing.T) {
+	token := common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+	bytes32 := make([]byte, 32)
+	copy(bytes32, "MKR")
+
+	for _, v := range []struct {
+		name     string
+		caller   *fakeCaller
+		expected string
+		err      bool
+	}{
+		{"bytes32", &fakeCaller{output: bytes32}, "MKR", false},
+		{"empty", &fakeCaller{}, "", false},
+		{"reverted", &fakeCaller{err: errors.New("execution reverted")}, "", false},
+		{"aggregated reverted", &fakeCaller{err: errCallReverted}, "", false},
+		{"network error", &fakeCaller{err: errors.New("connection refused")}, "", true},
+	} {
+		value, err := NewERC20(v.caller, CacheOption{}).queryString(token, "symbol")
+		if (err != nil) != v.err {
+			t.Fatalf("%v: unexpected error %v", v.name, err)
+		}
+
+		if value != v.expected {
+			t.Fatalf("%v: expected %q, got %q", v.name, v.expected, value)
+		}
+	}
+}
@@ -27,8 +27,8 @@ func main() {
 	defer client.Close()
 
 	caller, _ := client.ToClientForContract()
-	erc20 := blockchain.NewERC20(caller)
-	swappi := blockchain.NewSwappi(caller, erc20, swappiAddresses)
+	erc20 := blockchain.NewERC20(caller, blockchain.CacheOption{})
+	swappi := blockchain.NewSwappi(caller, erc20, swappiAddresses, blockchain.CacheOption{})
 
 	// get token info: USDT
 	token, err := erc20.GetTokenInfo(swappiAddresses.USDT)
@@ -0,0 +1,114 @@
+package blockchain
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// AirdropLeaf returns the Merkle leaf of (account, amount), which is compatible with OpenZeppelin's
+// StandardMerkleTree, i.e. keccak256(bytes.concat(keccak256(abi.encode(account, amount)))).
+func AirdropLeaf(account common.Address, amount *big.Int) common.Hash {
+	encoded := append(common.LeftPadBytes(account.Bytes(), 32), math.U256Bytes(new(big.Int).Set(amount))...)
+	return crypto.Keccak256Hash(crypto.Keccak256(encoded))
+}
+
+// MerkleTree is a sorted Merkle tree that hashes sorted pairs with promote-odd-node pairing, so that the proof could
+// be verified by OpenZeppelin's MerkleProof library. Note, it is NOT OpenZeppelin's StandardMerkleTree.
+type MerkleTree struct {
+	layers  [][]common.Hash // leaves in the first layer and root in the last layer
+	indices map[common.Hash]int
+}
+
+// NewMerkleTree builds a Merkle tree with given leaves, which will be sorted at first.
+//
+// Note, the last node of any layer will be promoted to the upper layer if no sibling node. So, the root differs from
+// OpenZeppelin's StandardMerkleTree (which is a complete binary tree), and StandardMerkleTree could not be used to
+// reproduce the root or proofs, though proofs could be verified on chain by OpenZeppelin's MerkleProof library.
+func NewMerkleTree(leaves []common.Hash) *MerkleTree {
+	sorted := make([]common.Hash, len(leaves))
+	copy(sorted, leaves)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Bytes(), sorted[j].Bytes()) < 0
+	})
+
+	tree := MerkleTree{
+		layers:  [][]common.Hash{sorted},
+		indices: make(map[common.Hash]int, len(sorted)),
+	}
+
+	for i, leaf := range sorted {
+		tree.indices[leaf] = i
+	}
+
+	for layer := sorted; len(layer) > 1; {
+		upper := make([]common.Hash, 0, (len(layer)+1)/2)
+
+		for i := 0; i < len(layer); i += 2 {
+			if i+1 < len(layer) {
+				upper = append(upper, hashSortedPair(layer[i], layer[i+1]))
+			} else {
+				upper = append(upper, layer[i])
+			}
+		}
+
+		tree.layers = append(tree.layers, upper)
+		layer = upper
+	}
+
+	return &tree
+}
+
+// Root returns the Merkle root, or empty hash if no leaf.
+func (tree *MerkleTree) Root() common.Hash {
+	top := tree.layers[len(tree.layers)-1]
+	if len(top) == 0 {
+		return common.Hash{}
+	}
+
+	return top[0]
+}
+
+// Proof returns the Merkle proof of given leaf, and false if leaf not found.
+func (tree *MerkleTree) Proof(leaf common.Hash) ([]common.Hash, bool) {
+	index, ok := tree.indices[leaf]
+	if !ok {
+		return nil, false
+	}
+
+	proof := []common.Hash{}
+
+	for _, layer := range tree.layers[:len(tree.layers)-1] {
+		sibling := index ^ 1
+		if sibling < len(layer) {
+			proof = append(proof, layer[sibling])
+		}
+
+		index /= 2
+	}
+
+	return proof, true
+}
+
+// VerifyMerkleProof verifies the Merkle proof in the same way as OpenZeppelin's MerkleProof.verify.
+func VerifyMerkleProof(proof []common.Hash, root, leaf common.Hash) bool {
+	computed := leaf
+
+	for _, v := range proof {
+		computed = hashSortedPair(computed, v)
+	}
+
+	return computed == root
+}
+
+func hashSortedPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a.Bytes(), b.Bytes()) < 0 {
+		return crypto.Keccak256Hash(a.Bytes(), b.Bytes())
+	}
+
+	return crypto.Keccak256Hash(b.Bytes(), a.Bytes())
+}
@@ -0,0 +1,42 @@
+package blockchain
+
+import (
+	"context"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/metrics"
+	"github.com/openweb3/go-rpc-provider"
+	providers "github.com/openweb3/go-rpc-provider/provider_wrapper"
+)
+
+// HookRPCMetrics reports the number of RPC calls, failures and latency by method of the provider, where name
+// distinguishes different RPC endpoints, e.g. blockchain or contract parser.
+func HookRPCMetrics(provider *providers.MiddlewarableProvider, name string) {
+	provider.HookCallContext(func(next providers.CallContextFunc) providers.CallContextFunc {
+		return func(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+			start := time.Now()
+			err := next(ctx, result, method, args...)
+			updateRPCMetrics(name, method, start, err)
+
+			return err
+		}
+	})
+
+	provider.HookBatchCallContext(func(next providers.BatchCallContextFunc) providers.BatchCallContextFunc {
+		return func(ctx context.Context, b []rpc.BatchElem) error {
+			start := time.Now()
+			err := next(ctx, b)
+			updateRPCMetrics(name, "batch", start, err)
+
+			return err
+		}
+	})
+}
+
+func updateRPCMetrics(name, method string, start time.Time, err error) {
+	metrics.GetOrRegisterTimer("rpc/%v/%v/latency", name, method).UpdateSince(start)
+
+	if err != nil {
+		metrics.GetOrRegisterCounter("rpc/%v/%v/errors", name, method).Inc(1)
+	}
+}
@@ -0,0 +1,218 @@
+package blockchain
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	stdSync "sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/v3-Swampy/points-service/blockchain/contract"
+)
+
+var errCallReverted = errors.New("execution reverted")
+
+// isCallReverted checks whether the contract call reverted, either in aggregated calls or reported by full node,
+// rather than failed due to network or server error.
+func isCallReverted(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, errCallReverted) {
+		return true
+	}
+
+	return strings.Contains(err.Error(), errCallReverted.Error())
+}
+
+// MulticallConfig is the configurations of Multicall3 contract to aggregate on-chain reads.
+type MulticallConfig struct {
+	Address   string `default:"0xcA11bde05977b3631167028862bE2a173976CA11"` // same address on most EVM chains
+	BatchSize int    `default:"500"`                                        // maximum number of calls in a request
+}
+
+// Call is a contract call to aggregate.
+type Call struct {
+	Target common.Address
+	Data   []byte
+}
+
+// CallResult is the result of an aggregated contract call.
+type CallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// Multicall aggregates multiple contract calls into a single eth_call via Multicall3.
+type Multicall struct {
+	caller    contract.Multicall3CallerRaw
+	batchSize int
+}
+
+func NewMulticall(caller bind.ContractCaller, config MulticallConfig) (*Multicall, error) {
+	multicallCaller, err := contract.NewMulticall3Caller(common.HexToAddress(config.Address), caller)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to create Multicall3 caller")
+	}
+
+	batchSize := config.BatchSize
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	return &Multicall{
+		caller:    contract.Multicall3CallerRaw{Contract: multicallCaller},
+		batchSize: batchSize,
+	}, nil
+}
+
+// Aggregate executes calls at the same block, and calls are split into multiple requests if too many. Note, a
+// failed call will not fail others, and result of failed call is returned with Success = false.
+func (multicall *Multicall) Aggregate(opts *bind.CallOpts, calls []Call) ([]CallResult, error) {
+	results := make([]CallResult, 0, len(calls))
+
+	for start := 0; start < len(calls); start += multicall.batchSize {
+		end := min(start+multicall.batchSize, len(calls))
+
+		batch := make([]contract.Multicall3Call3, 0, end-start)
+		for _, v := range calls[start:end] {
+			batch = append(batch, contract.Multicall3Call3{
+				Target:       v.Target,
+				AllowFailure: true,
+				CallData:     v.Data,
+			})
+		}
+
+		var out []any
+		if err := multicall.caller.Call(opts, &out, "aggregate3", batch); err != nil {
+			return nil, errors.WithMessage(err, "Failed to call aggregate3")
+		}
+
+		batchResults := *abi.ConvertType(out[0], new([]contract.Multicall3Result)).(*[]contract.Multicall3Result)
+		if len(batchResults) != len(batch) {
+			return nil, errors.Errorf("Number of results mismatch, expected = %v, actual = %v", len(batch), len(batchResults))
+		}
+
+		for _, v := range batchResults {
+			results = append(results, CallResult{v.Success, v.ReturnData})
+		}
+	}
+
+	return results, nil
+}
+
+type callKey struct {
+	to   common.Address
+	data string
+}
+
+// BatchCaller is a contract caller to read on-chain states at a specific block, in which calls could be prefetched
+// in batch via Multicall3. Calls that not prefetched will be delegated to the underlying caller, and all results
+// at the block are memoized.
+type BatchCaller struct {
+	bind.ContractCaller
+
+	multicall   *Multicall // nil to disable batch
+	blockNumber *big.Int
+
+	mu      stdSync.Mutex
+	results map[callKey]CallResult
+}
+
+var _ bind.ContractCaller = (*BatchCaller)(nil)
+
+func NewBatchCaller(caller bind.ContractCaller, multicall *Multicall, blockNumber uint64) *BatchCaller {
+	return &BatchCaller{
+		ContractCaller: caller,
+		multicall:      multicall,
+		blockNumber:    new(big.Int).SetUint64(blockNumber),
+		results:        make(map[callKey]CallResult),
+	}
+}
+
+// Opts returns the call options at the block of batch caller.
+func (caller *BatchCaller) Opts() *bind.CallOpts {
+	return &bind.CallOpts{
+		BlockNumber: new(big.Int).Set(caller.blockNumber),
+	}
+}
+
+// Prefetch aggregates calls that not memoized yet into Multicall3 requests.
+func (caller *BatchCaller) Prefetch(calls []Call) error {
+	if caller.multicall == nil {
+		return nil
+	}
+
+	caller.mu.Lock()
+	var pending []Call
+	seen := make(map[callKey]bool)
+	for _, v := range calls {
+		key := callKey{v.Target, string(v.Data)}
+		if _, ok := caller.results[key]; !ok && !seen[key] {
+			seen[key] = true
+			pending = append(pending, v)
+		}
+	}
+	caller.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	results, err := caller.multicall.Aggregate(caller.Opts(), pending)
+	if err != nil {
+		return err
+	}
+
+	caller.mu.Lock()
+	defer caller.mu.Unlock()
+
+	for i, v := range pending {
+		caller.results[callKey{v.Target, string(v.Data)}] = results[i]
+	}
+
+	return nil
+}
+
+// result returns the memoized call result if any.
+func (caller *BatchCaller) result(call Call) (CallResult, bool) {
+	caller.mu.Lock()
+	defer caller.mu.Unlock()
+
+	result, ok := caller.results[callKey{call.Target, string(call.Data)}]
+
+	return result, ok
+}
+
+// CallContract implements the bind.ContractCaller interface.
+func (caller *BatchCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	// not at the block of batch caller, e.g. query token metadata at the latest block
+	if call.To == nil || blockNumber == nil || blockNumber.Cmp(caller.blockNumber) != 0 {
+		return caller.ContractCaller.CallContract(ctx, call, blockNumber)
+	}
+
+	key := Call{*call.To, call.Data}
+	if result, ok := caller.result(key); ok {
+		if !result.Success {
+			return nil, errCallReverted
+		}
+
+		return result.ReturnData, nil
+	}
+
+	output, err := caller.ContractCaller.CallContract(ctx, call, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	caller.mu.Lock()
+	caller.results[callKey{key.Target, string(key.Data)}] = CallResult{true, output}
+	caller.mu.Unlock()
+
+	return output, nil
+}
@@ -0,0 +1,70 @@
+package blockchain
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitConfig is the configurations to limit the rate of RPC requests to blockchain, e.g. eth_call and
+// eth_getCode, so as to avoid being throttled by full node when catching up.
+type RateLimitConfig struct {
+	RPS   float64 // maximum number of requests per second, 0 for unlimited
+	Burst int     `default:"10"` // maximum number of requests in burst
+}
+
+// RateLimitedCaller is a contract caller that waits for rate limit before delegating to the underlying caller.
+type RateLimitedCaller struct {
+	caller  bind.ContractCaller
+	limiter *rate.Limiter
+}
+
+var _ bind.ContractCaller = (*RateLimitedCaller)(nil)
+
+// NewRateLimitedCaller returns the underlying caller directly if rate limit disabled.
+func NewRateLimitedCaller(caller bind.ContractCaller, config RateLimitConfig) bind.ContractCaller {
+	if config.RPS <= 0 {
+		return caller
+	}
+
+	burst := config.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &RateLimitedCaller{
+		caller:  caller,
+		limiter: rate.NewLimiter(rate.Limit(config.RPS), burst),
+	}
+}
+
+// CodeAt implements the bind.ContractCaller interface.
+func (caller *RateLimitedCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if err := caller.limiter.Wait(contextOrBackground(ctx)); err != nil {
+		return nil, err
+	}
+
+	return caller.caller.CodeAt(ctx, contract, blockNumber)
+}
+
+// CallContract implements the bind.ContractCaller interface.
+func (caller *RateLimitedCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if err := caller.limiter.Wait(contextOrBackground(ctx)); err != nil {
+		return nil, err
+	}
+
+	return caller.caller.CallContract(ctx, call, blockNumber)
+}
+
+// contextOrBackground returns background context if nil, which is the case of bind.CallOpts without context.
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+
+	return ctx
+}
@@ -0,0 +1,143 @@
+package blockchain
+
+import (
+	"slices"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/v3-Swampy/points-service/blockchain/contract"
+)
+
+// BlockReader reads token prices and pool TVL at a specific block, in which on-chain reads could be prefetched
+// and aggregated into a single Multicall3 request.
+type BlockReader struct {
+	caller *BatchCaller
+
+	Swappi *Swappi
+	Vswap  *Vswap
+}
+
+// NewBlockReader creates a reader to read on-chain states at given block number. Note, reads are not aggregated
+// if Multicall3 not configured.
+func (vswap *Vswap) NewBlockReader(blockNumber uint64) *BlockReader {
+	caller := NewBatchCaller(vswap.swappi.caller, vswap.multicall, blockNumber)
+	swappi := vswap.swappi.withCaller(caller)
+
+	return &BlockReader{
+		caller: caller,
+		Swappi: swappi,
+		Vswap:  vswap.withSwappi(swappi),
+	}
+}
+
+// Opts returns the call options at the block of reader.
+func (reader *BlockReader) Opts() *bind.CallOpts {
+	return reader.caller.Opts()
+}
+
+// Prefetch aggregates reads to calculate TVL of pools and Swappi prices of tokens. Generally, it requires only one
+// request, unless any Swappi pair is newly found, which requires another request to read reserves.
+func (reader *BlockReader) Prefetch(pools, tokens []common.Address) error {
+	erc20Abi, err := contract.ERC20MetaData.GetAbi()
+	if err != nil {
+		return errors.WithMessage(err, "Failed to get ERC20 ABI")
+	}
+
+	factoryAbi, err := contract.SwappiFactoryMetaData.GetAbi()
+	if err != nil {
+		return errors.WithMessage(err, "Failed to get Swappi factory ABI")
+	}
+
+	pairAbi, err := contract.SwappiPairMetaData.GetAbi()
+	if err != nil {
+		return errors.WithMessage(err, "Failed to get Swappi pair ABI")
+	}
+
+	// arguments are always valid to pack
+	var calls []Call
+	pack := func(target common.Address, contractAbi *abi.ABI, method string, args ...any) []byte {
+		data, _ := contractAbi.Pack(method, args...)
+		calls = append(calls, Call{target, data})
+		return data
+	}
+
+	// pool balances to calculate TVL and token prices in Vswap
+	for _, pool := range append(slices.Clone(pools), reader.Vswap.wcfxUsdtPool) {
+		info, err := reader.Vswap.GetPoolInfo(pool)
+		if err != nil {
+			return errors.WithMessage(err, "Failed to get pool info")
+		}
+
+		pack(info.Token0.Address, erc20Abi, "balanceOf", pool)
+		pack(info.Token1.Address, erc20Abi, "balanceOf", pool)
+	}
+
+	// pairs and reserves to calculate token prices in Swappi
+	routes := reader.Swappi.priceRoutes(tokens)
+	pairCalls := make(map[swappiPairKey][]byte, len(routes))
+	for _, key := range routes {
+		pairCalls[key] = pack(reader.Swappi.addresses.Factory, factoryAbi, "getPair", key.base, key.quote)
+
+		if pair, ok := reader.Swappi.pairs.Load(key); ok {
+			pack(pair.(common.Address), pairAbi, "getReserves")
+		}
+	}
+
+	if err = reader.caller.Prefetch(calls); err != nil {
+		return err
+	}
+
+	// reserves of newly found pairs
+	calls = nil
+	for _, key := range routes {
+		if _, ok := reader.Swappi.pairs.Load(key); ok {
+			continue
+		}
+
+		result, ok := reader.caller.result(Call{reader.Swappi.addresses.Factory, pairCalls[key]})
+		if !ok || !result.Success {
+			continue
+		}
+
+		values, err := factoryAbi.Unpack("getPair", result.ReturnData)
+		if err != nil || len(values) == 0 {
+			continue
+		}
+
+		if pair := values[0].(common.Address); pair != (common.Address{}) {
+			pack(pair, pairAbi, "getReserves")
+		}
+	}
+
+	return reader.caller.Prefetch(calls)
+}
+
+// priceRoutes returns the pairs to calculate token prices in order of GetTokenPriceAutoWithRoute.
+func (swappi *Swappi) priceRoutes(tokens []common.Address) []swappiPairKey {
+	usdt, wcfx := swappi.addresses.USDT, swappi.addresses.WCFX
+
+	var keys []swappiPairKey
+	seen := make(map[swappiPairKey]bool)
+	add := func(base, quote common.Address) {
+		if key := (swappiPairKey{base, quote}); !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for _, token := range tokens {
+		switch token {
+		case usdt:
+		case wcfx:
+			add(wcfx, usdt)
+		default:
+			add(token, wcfx)
+			add(wcfx, usdt)
+			add(token, usdt)
+		}
+	}
+
+	return keys
+}
@@ -1,18 +1,34 @@
 package scan
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/mcuadros/go-defaults"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 )
 
 type Option struct {
 	ApiKey         string
+	ApiKeys        []string // rotated on quota errors, along with ApiKey if any
 	DebugEnabled   bool
 	RequestTimeout time.Duration `default:"3s"`
+
+	RPS   float64 // maximum number of requests per second, 0 for unlimited
+	Burst int     `default:"1"` // maximum number of requests in burst
+
+	MaxRetries       int           `default:"3"`   // maximum number of retries on rate limit or transient errors
+	RetryInterval    time.Duration `default:"1s"`  // backoff before the first retry, which doubles for each retry
+	MaxRetryInterval time.Duration `default:"10s"` // maximum backoff between retries
 }
 
 type Response[T any] struct {
@@ -24,18 +40,21 @@ type Response[T any] struct {
 func (resp *Response[T]) GetResult() (v T, err error) {
 	if resp.Status == "1" {
 		v = resp.Result
-	} else if len(resp.Status) == 0 {
-		err = errors.Errorf("Scan Error: %v", resp.Message)
 	} else {
-		err = errors.Errorf("Scan Error (%v): %v", resp.Status, resp.Message)
+		err = classifyError(resp.Status, resp.Message)
 	}
 
 	return
 }
 
 type Api struct {
-	option Option
-	client *resty.Client
+	option  Option
+	client  *resty.Client
+	limiter *rate.Limiter // nil if unlimited
+
+	mu     sync.Mutex
+	keys   []string
+	keyIdx int
 }
 
 func NewApi(url string, option ...Option) *Api {
@@ -46,28 +65,160 @@ func NewApi(url string, option ...Option) *Api {
 
 	defaults.SetDefaults(&opt)
 
+	var keys []string
+	for _, v := range append([]string{opt.ApiKey}, opt.ApiKeys...) {
+		if len(v) > 0 && !slices.Contains(keys, v) {
+			keys = append(keys, v)
+		}
+	}
+
+	var limiter *rate.Limiter
+	if opt.RPS > 0 {
+		limiter = rate.NewLimiter(rate.Limit(opt.RPS), max(opt.Burst, 1))
+	}
+
 	return &Api{
 		option: opt,
 		client: resty.New().
 			SetBaseURL(url).
 			SetDebug(opt.DebugEnabled).
 			SetTimeout(opt.RequestTimeout),
+		limiter: limiter,
+		keys:    keys,
 	}
 }
 
 func (api *Api) GetBlockNumberByTime(timestampSecs int64, after bool) (uint64, error) {
+	return api.GetBlockNumberByTimeContext(context.Background(), timestampSecs, after)
+}
+
+// GetBlockNumberByTimeContext is the same as GetBlockNumberByTime, but stops waiting for rate limit or retry
+// once the given context is done.
+func (api *Api) GetBlockNumberByTimeContext(ctx context.Context, timestampSecs int64, after bool) (uint64, error) {
 	closest := "before"
 	if after {
 		closest = "after"
 	}
 
-	url := fmt.Sprintf("/api?module=block&action=getblocknobytime&timestamp=%v&closest=%v&apiKey=%v", timestampSecs, closest, api.option.ApiKey)
+	params := url.Values{}
+	params.Set("module", "block")
+	params.Set("action", "getblocknobytime")
+	params.Set("timestamp", fmt.Sprint(timestampSecs))
+	params.Set("closest", closest)
+
+	return get[uint64](ctx, api, params)
+}
+
+// currentKey returns the API key in use along with its index, or empty if no key configured.
+func (api *Api) currentKey() (string, int) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if len(api.keys) == 0 {
+		return "", 0
+	}
+
+	return api.keys[api.keyIdx], api.keyIdx
+}
+
+// rotateKey switches to the next API key, unless already rotated by others concurrently.
+func (api *Api) rotateKey(idx int) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if api.keyIdx == idx {
+		api.keyIdx = (idx + 1) % len(api.keys)
+	}
+}
+
+// get requests scan with rate limit, and retries with backoff on rate limit or transient errors. Besides, API key
+// will be rotated on quota errors, until all keys tried.
+func get[T any](ctx context.Context, api *Api, params url.Values) (T, error) {
+	var result T
+	var err error
+
+	backoff := api.option.RetryInterval
+	retries, rotations := 0, 0
+
+	for {
+		key, idx := api.currentKey()
+
+		if result, err = request[T](ctx, api, params, key); err == nil {
+			return result, nil
+		}
+
+		if errors.Is(err, ErrQuotaExceeded) && rotations+1 < len(api.keys) {
+			logrus.WithError(err).WithField("key", idx).Warn("Scan API key out of quota, rotate to the next one")
+			api.rotateKey(idx)
+			rotations++
+			continue
+		}
+
+		if !retryable(err) || retries >= api.option.MaxRetries {
+			return result, err
+		}
+
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"action":  params.Get("action"),
+			"retries": retries,
+			"backoff": backoff,
+		}).Debug("Failed to request scan, retry later")
+
+		select {
+		case <-ctx.Done():
+			return result, errors.WithMessage(ctx.Err(), err.Error())
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, api.option.MaxRetryInterval)
+		retries++
+	}
+}
+
+// request sends a request to scan once, and decodes the result.
+func request[T any](ctx context.Context, api *Api, params url.Values, key string) (T, error) {
+	var result T
+
+	if api.limiter != nil {
+		if err := api.limiter.Wait(ctx); err != nil {
+			return result, err
+		}
+	}
+
+	req := api.client.R().SetContext(ctx).SetQueryParamsFromValues(params)
+	if len(key) > 0 {
+		req.SetQueryParam("apiKey", key)
+	}
+
+	httpResp, err := req.Get("/api")
+	if err != nil {
+		return result, err
+	}
 
-	var resp Response[uint64]
+	if httpResp.IsError() {
+		return result, classifyHttpStatus(httpResp.StatusCode(), strings.TrimSpace(httpResp.String()))
+	}
+
+	// decode regardless of content type
+	var resp Response[json.RawMessage]
+	if err = json.Unmarshal(httpResp.Body(), &resp); err != nil {
+		return result, errors.WithMessagef(err, "Failed to decode scan response %v", httpResp.String())
+	}
+
+	if resp.Status != "1" {
+		// error details may be returned in result, e.g. "Max rate limit reached"
+		message := resp.Message
+		var detail string
+		if json.Unmarshal(resp.Result, &detail) == nil && len(detail) > 0 {
+			message = fmt.Sprintf("%v: %v", message, detail)
+		}
+
+		return result, classifyError(resp.Status, message)
+	}
 
-	if _, err := api.client.R().SetResult(&resp).Get(url); err != nil {
-		return 0, err
+	if err = json.Unmarshal(resp.Result, &result); err != nil {
+		return result, errors.WithMessagef(err, "Failed to decode scan result %v", string(resp.Result))
 	}
 
-	return resp.GetResult()
+	return result, nil
 }
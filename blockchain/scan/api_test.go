@@ -0,0 +1,184 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestApi creates an API client against a fake scan server, which serves requests with the given handler
+// and counts the number of requests.
+func newTestApi(t *testing.T, option Option, handler func(n int64, w http.ResponseWriter, r *http.Request)) (*Api, *atomic.Int64) {
+	t.Helper()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(requests.Add(1), w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	if option.RetryInterval == 0 {
+		option.RetryInterval = time.Millisecond
+	}
+
+	return NewApi(server.URL, option), &requests
+}
+
+func writeOK(w http.ResponseWriter, bn uint64) {
+	fmt.Fprintf(w, `{"status":"1","message":"OK","result":%v}`, bn)
+}
+
+func writeNotOK(w http.ResponseWriter, detail string) {
+	fmt.Fprintf(w, `{"status":"0","message":"NOTOK","result":%q}`, detail)
+}
+
+func TestApiRetryOnRateLimit(t *testing.T) {
+	api, requests := newTestApi(t, Option{}, func(n int64, w http.ResponseWriter, r *http.Request) {
+		switch n {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			writeNotOK(w, "Max calls per sec rate limit reached (5/sec)")
+		case 3:
+			// rate limited without API key
+			writeNotOK(w, "Max rate limit reached, please use API Key for higher rate limit")
+		default:
+			writeOK(w, 123)
+		}
+	})
+
+	bn, err := api.GetBlockNumberByTime(1700000000, true)
+	if err != nil || bn != 123 {
+		t.Fatalf("Unexpected block number %v, err = %v", bn, err)
+	}
+
+	if n := requests.Load(); n != 4 {
+		t.Fatalf("Unexpected number of requests %v", n)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	for _, v := range []struct {
+		message  string
+		expected error
+	}{
+		{"Max calls per sec rate limit reached (5/sec)", ErrRateLimited},
+		{"Max rate limit reached, please use API Key for higher rate limit", ErrRateLimited},
+		{"Max daily rate limit reached", ErrQuotaExceeded},
+		{"Invalid API Key", ErrQuotaExceeded},
+		{"Missing Or invalid Module name", ErrInvalidParams},
+	} {
+		if err := classifyError("0", v.message); !errors.Is(err, v.expected) {
+			t.Errorf("Unexpected error of message %q, expected = %v, actual = %v", v.message, v.expected, err)
+		}
+	}
+}
+
+func TestApiRotateKeyOnQuotaExceeded(t *testing.T) {
+	var keys []string
+	api, _ := newTestApi(t, Option{ApiKey: "a", ApiKeys: []string{"b", "c"}}, func(n int64, w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("apiKey")
+		keys = append(keys, key)
+
+		if key == "a" {
+			writeNotOK(w, "Max daily rate limit reached")
+		} else {
+			writeOK(w, 456)
+		}
+	})
+
+	for range 2 {
+		bn, err := api.GetBlockNumberByTime(1700000000, false)
+		if err != nil || bn != 456 {
+			t.Fatalf("Unexpected block number %v, err = %v", bn, err)
+		}
+	}
+
+	// key rotated permanently rather than per request
+	if fmt.Sprint(keys) != "[a b b]" {
+		t.Fatalf("Unexpected keys in use %v", keys)
+	}
+}
+
+func TestApiAllKeysExceeded(t *testing.T) {
+	api, requests := newTestApi(t, Option{ApiKey: "a", ApiKeys: []string{"b"}}, func(n int64, w http.ResponseWriter, r *http.Request) {
+		writeNotOK(w, "Max daily rate limit reached")
+	})
+
+	if _, err := api.GetBlockNumberByTime(1700000000, false); !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if n := requests.Load(); n != 2 {
+		t.Fatalf("Unexpected number of requests %v", n)
+	}
+}
+
+func TestApiInvalidParams(t *testing.T) {
+	api, requests := newTestApi(t, Option{}, func(n int64, w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":"0","message":"Invalid parameter timestamp","result":null}`)
+	})
+
+	if _, err := api.GetBlockNumberByTime(-1, true); !errors.Is(err, ErrInvalidParams) {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if n := requests.Load(); n != 1 {
+		t.Fatalf("Invalid params retried, requests = %v", n)
+	}
+}
+
+func TestApiMaxRetries(t *testing.T) {
+	api, requests := newTestApi(t, Option{MaxRetries: 2}, func(n int64, w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	if _, err := api.GetBlockNumberByTime(1700000000, true); err == nil {
+		t.Fatal("Error expected")
+	}
+
+	if n := requests.Load(); n != 3 {
+		t.Fatalf("Unexpected number of requests %v", n)
+	}
+}
+
+func TestApiCancelBackoff(t *testing.T) {
+	api, _ := newTestApi(t, Option{RetryInterval: time.Hour}, func(n int64, w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := api.GetBlockNumberByTimeContext(ctx, 1700000000, true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Backoff not cancelled, elapsed = %v", elapsed)
+	}
+}
+
+func TestApiRateLimiter(t *testing.T) {
+	api, _ := newTestApi(t, Option{RPS: 20, Burst: 1}, func(n int64, w http.ResponseWriter, r *http.Request) {
+		writeOK(w, 1)
+	})
+
+	start := time.Now()
+	for range 5 {
+		if _, err := api.GetBlockNumberByTime(1700000000, true); err != nil {
+			t.Fatalf("Failed to get block number: %v", err)
+		}
+	}
+
+	// 4 requests wait for 50ms each
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("Requests not rate limited, elapsed = %v", elapsed)
+	}
+}
@@ -0,0 +1,61 @@
+package scan
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrRateLimited is returned when requests exceed the rate limit of API key, which could be retried later.
+	ErrRateLimited = errors.New("Scan rate limited")
+
+	// ErrQuotaExceeded is returned when API key is out of quota or invalid, which could be retried with another key.
+	ErrQuotaExceeded = errors.New("Scan quota exceeded")
+
+	// ErrInvalidParams is returned when request parameters are invalid, which should not be retried.
+	ErrInvalidParams = errors.New("Scan invalid params")
+)
+
+// classifyError wraps the error message of scan with a typed error if recognized.
+//
+// Note, rate limit is checked before API key, since the rate limit message without API key mentions API key, e.g.
+// "Max rate limit reached, please use API Key for higher rate limit".
+func classifyError(status, message string) error {
+	text := strings.ToLower(message)
+
+	switch {
+	case strings.Contains(text, "daily"), strings.Contains(text, "quota"):
+		return errors.WithMessage(ErrQuotaExceeded, message)
+	case strings.Contains(text, "rate limit"), strings.Contains(text, "too many"):
+		return errors.WithMessage(ErrRateLimited, message)
+	case strings.Contains(text, "api key"), strings.Contains(text, "apikey"):
+		return errors.WithMessage(ErrQuotaExceeded, message)
+	case strings.Contains(text, "invalid"), strings.Contains(text, "missing"), strings.Contains(text, "required"):
+		return errors.WithMessage(ErrInvalidParams, message)
+	case len(status) == 0:
+		return errors.Errorf("Scan Error: %v", message)
+	default:
+		return errors.Errorf("Scan Error (%v): %v", status, message)
+	}
+}
+
+// classifyHttpStatus returns typed error of unsuccessful HTTP status code.
+func classifyHttpStatus(code int, body string) error {
+	switch {
+	case code == http.StatusTooManyRequests:
+		return errors.WithMessage(ErrRateLimited, body)
+	case code == http.StatusUnauthorized, code == http.StatusForbidden:
+		return errors.WithMessage(ErrQuotaExceeded, body)
+	case code == http.StatusBadRequest:
+		return errors.WithMessage(ErrInvalidParams, body)
+	default:
+		return errors.Errorf("Scan HTTP Error (%v): %v", code, body)
+	}
+}
+
+// retryable returns whether the failed request could be retried with the same API key.
+func retryable(err error) bool {
+	return !errors.Is(err, ErrInvalidParams) && !errors.Is(err, ErrQuotaExceeded)
+}
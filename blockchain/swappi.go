@@ -2,6 +2,7 @@ package blockchain
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -28,54 +29,93 @@ type SwappiAddresses struct {
 	WCFX    common.Address
 }
 
+// pairTokens is the cached token addresses of pair, whose token info is cached separately.
+type pairTokens struct {
+	Token0 common.Address
+	Token1 common.Address
+}
+
 type Swappi struct {
-	cacheable[common.Address, PairInfo]
+	cacheable[common.Address, pairTokens]
 
 	caller    bind.ContractCaller
 	erc20     *ERC20
 	addresses SwappiAddresses
+	pairs     *sync.Map // known pairs in factory, swappiPairKey => pair address
+}
+
+type swappiPairKey struct {
+	base  common.Address
+	quote common.Address
 }
 
-func NewSwappi(caller bind.ContractCaller, erc20 *ERC20, addresses SwappiAddresses) *Swappi {
+func NewSwappi(caller bind.ContractCaller, erc20 *ERC20, addresses SwappiAddresses, cache CacheOption) *Swappi {
 	return &Swappi{
+		cacheable: newCacheable[common.Address, pairTokens](CacheNamespacePair, cache),
 		caller:    caller,
 		erc20:     erc20,
 		addresses: addresses,
+		pairs:     new(sync.Map),
 	}
 }
 
+// withCaller returns a copy of Swappi with the given caller, which shares the same cache.
+func (swappi *Swappi) withCaller(caller bind.ContractCaller) *Swappi {
+	copied := *swappi
+	copied.caller = caller
+	copied.erc20 = swappi.erc20.withCaller(caller)
+
+	return &copied
+}
+
 // GetPairInfo retrieves pair token info from blockchain or returns the cached value.
 func (swappi *Swappi) GetPairInfo(pair common.Address) (PairInfo, error) {
-	return swappi.getOrQueryFunc(pair, swappi.GetPairInfoForce)
+	tokens, err := swappi.getOrQueryFunc(pair, swappi.queryPairTokens)
+	if err != nil {
+		return PairInfo{}, err
+	}
+
+	return swappi.newPairInfo(pair, tokens)
 }
 
 func (swappi *Swappi) GetPairInfoForce(pair common.Address) (PairInfo, error) {
-	info := PairInfo{
-		Address: pair,
+	tokens, err := swappi.queryPairTokens(pair)
+	if err != nil {
+		return PairInfo{}, err
 	}
 
-	// retrieves token0 & token1 from pair token
+	return swappi.newPairInfo(pair, tokens)
+}
+
+// queryPairTokens retrieves token0 & token1 from pair token.
+func (swappi *Swappi) queryPairTokens(pair common.Address) (pairTokens, error) {
 	pairCaller, err := contract.NewSwappiPairCaller(pair, swappi.caller)
 	if err != nil {
-		return PairInfo{}, errors.WithMessage(err, "Failed to create Pair caller")
+		return pairTokens{}, errors.WithMessage(err, "Failed to create Pair caller")
 	}
 
 	token0, err := pairCaller.Token0(nil)
 	if err != nil {
-		return PairInfo{}, errors.WithMessage(err, "Failed to query token0 of LP token")
+		return pairTokens{}, errors.WithMessage(err, "Failed to query token0 of LP token")
 	}
 
 	token1, err := pairCaller.Token1(nil)
 	if err != nil {
-		return PairInfo{}, errors.WithMessage(err, "Failed to query token1 of LP token")
+		return pairTokens{}, errors.WithMessage(err, "Failed to query token1 of LP token")
 	}
 
-	// retrieves token info of token0 and token1
-	if info.Token0, err = swappi.erc20.GetTokenInfo(token0); err != nil {
+	return pairTokens{token0, token1}, nil
+}
+
+// newPairInfo retrieves token info of token0 and token1 from cache.
+func (swappi *Swappi) newPairInfo(pair common.Address, tokens pairTokens) (info PairInfo, err error) {
+	info.Address = pair
+
+	if info.Token0, err = swappi.erc20.GetTokenInfo(tokens.Token0); err != nil {
 		return PairInfo{}, errors.WithMessage(err, "Failed to query token0 info")
 	}
 
-	if info.Token1, err = swappi.erc20.GetTokenInfo(token1); err != nil {
+	if info.Token1, err = swappi.erc20.GetTokenInfo(tokens.Token1); err != nil {
 		return PairInfo{}, errors.WithMessage(err, "Failed to query token1 info")
 	}
 
@@ -103,6 +143,9 @@ func (swappi *Swappi) GetTokenPrice(opts *bind.CallOpts, baseToken, quoteToken c
 		return decimal.Zero, ErrSwappiPairNotFound
 	}
 
+	// pair will never be changed once created
+	swappi.pairs.Store(swappiPairKey{baseToken, quoteToken}, pair)
+
 	info, err := swappi.GetPairInfo(pair)
 	if err != nil {
 		return decimal.Zero, errors.WithMessage(err, "Failed to get pair info")
@@ -168,31 +211,45 @@ func (swappi *Swappi) GetTokenPriceRouted(opts *bind.CallOpts, routes ...common.
 //
 // Note, it returns 0 if any token reserve is 0.
 func (swappi *Swappi) GetTokenPriceAuto(opts *bind.CallOpts, token common.Address) (decimal.Decimal, error) {
+	price, _, err := swappi.GetTokenPriceAutoWithRoute(opts, token)
+	return price, err
+}
+
+// GetTokenPriceAutoWithRoute is the same as GetTokenPriceAuto, but also returns the token route used to calculate
+// the price, e.g. [token, WCFX, USDT].
+func (swappi *Swappi) GetTokenPriceAutoWithRoute(opts *bind.CallOpts, token common.Address) (decimal.Decimal, []common.Address, error) {
 	if token == swappi.addresses.USDT {
-		return decimal.NewFromInt(1), nil
+		return decimal.NewFromInt(1), []common.Address{token}, nil
 	}
 
 	if token == swappi.addresses.WCFX {
-		return swappi.GetTokenPrice(opts, swappi.addresses.WCFX, swappi.addresses.USDT)
+		route := []common.Address{swappi.addresses.WCFX, swappi.addresses.USDT}
+		price, err := swappi.GetTokenPrice(opts, swappi.addresses.WCFX, swappi.addresses.USDT)
+		if err != nil {
+			return decimal.Zero, nil, err
+		}
+
+		return price, route, nil
 	}
 
 	// try to get price by token/WCFX/USDT with priority
-	price, err := swappi.GetTokenPriceRouted(opts, token, swappi.addresses.WCFX, swappi.addresses.USDT)
+	route := []common.Address{token, swappi.addresses.WCFX, swappi.addresses.USDT}
+	price, err := swappi.GetTokenPriceRouted(opts, route...)
 	if err == nil {
-		return price, nil
+		return price, route, nil
 	}
 
 	if err != ErrSwappiPairNotFound {
-		return decimal.Zero, errors.WithMessage(err, "Failed to get price by token/WCFX/USDT")
+		return decimal.Zero, nil, errors.WithMessage(err, "Failed to get price by token/WCFX/USDT")
 	}
 
 	// otherwise, try to get price by token/USDT
 	price, err = swappi.GetTokenPrice(opts, token, swappi.addresses.USDT)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to get price by token/USDT")
+		return decimal.Zero, nil, errors.WithMessage(err, "Failed to get price by token/USDT")
 	}
 
-	return price, nil
+	return price, []common.Address{token, swappi.addresses.USDT}, nil
 }
 
 // GetPairTVL calculates the TVL of given pair via reserves.
@@ -22,47 +22,86 @@ func (info PoolInfo) String() string {
 	return fmt.Sprintf("%v/%v/%v", info.Token0.Symbol, info.Token1.Symbol, info.Fee)
 }
 
+// poolMetadata is the cached token addresses and fee of pool, whose token info is cached separately.
+type poolMetadata struct {
+	pairTokens
+
+	Fee uint32
+}
+
 type Vswap struct {
-	cacheable[common.Address, PoolInfo]
+	cacheable[common.Address, poolMetadata]
 
 	swappi       *Swappi
 	wcfxUsdtPool common.Address
+	multicall    *Multicall // optional to aggregate reads at a block
 }
 
-func NewVswap(swappi *Swappi, wcfxUsdtPool common.Address) *Vswap {
+func NewVswap(swappi *Swappi, wcfxUsdtPool common.Address, multicall *Multicall, cache CacheOption) *Vswap {
 	return &Vswap{
+		cacheable:    newCacheable[common.Address, poolMetadata](CacheNamespacePool, cache),
 		swappi:       swappi,
 		wcfxUsdtPool: wcfxUsdtPool,
+		multicall:    multicall,
 	}
 }
 
+// withSwappi returns a copy of Vswap with the given Swappi, which shares the same cache.
+func (vswap *Vswap) withSwappi(swappi *Swappi) *Vswap {
+	copied := *vswap
+	copied.swappi = swappi
+
+	return &copied
+}
+
 // GetPoolInfo retrieves pool info from blockchain or returns the cached value.
 func (vswap *Vswap) GetPoolInfo(pool common.Address) (PoolInfo, error) {
-	return vswap.getOrQueryFunc(pool, vswap.GetPoolInfoForce)
+	metadata, err := vswap.getOrQueryFunc(pool, vswap.queryPoolMetadata)
+	if err != nil {
+		return PoolInfo{}, err
+	}
+
+	return vswap.newPoolInfo(pool, metadata)
 }
 
 func (vswap *Vswap) GetPoolInfoForce(pool common.Address) (PoolInfo, error) {
-	pairInfo, err := vswap.swappi.GetPairInfoForce(pool)
+	metadata, err := vswap.queryPoolMetadata(pool)
 	if err != nil {
 		return PoolInfo{}, err
 	}
 
+	return vswap.newPoolInfo(pool, metadata)
+}
+
+func (vswap *Vswap) queryPoolMetadata(pool common.Address) (poolMetadata, error) {
+	tokens, err := vswap.swappi.queryPairTokens(pool)
+	if err != nil {
+		return poolMetadata{}, err
+	}
+
 	poolCaller, err := contract.NewUniswapV3PoolCaller(pool, vswap.swappi.caller)
 	if err != nil {
-		return PoolInfo{}, errors.WithMessage(err, "Failed to create Pool caller")
+		return poolMetadata{}, errors.WithMessage(err, "Failed to create Pool caller")
 	}
 
 	fee, err := poolCaller.Fee(nil)
 	if err != nil {
-		return PoolInfo{}, errors.WithMessage(err, "Failed to query pool fee")
+		return poolMetadata{}, errors.WithMessage(err, "Failed to query pool fee")
 	}
 
-	info := PoolInfo{
-		PairInfo: pairInfo,
-		Fee:      uint32(fee.Uint64()),
+	return poolMetadata{tokens, uint32(fee.Uint64())}, nil
+}
+
+func (vswap *Vswap) newPoolInfo(pool common.Address, metadata poolMetadata) (PoolInfo, error) {
+	pairInfo, err := vswap.swappi.newPairInfo(pool, metadata.pairTokens)
+	if err != nil {
+		return PoolInfo{}, err
 	}
 
-	return info, nil
+	return PoolInfo{
+		PairInfo: pairInfo,
+		Fee:      metadata.Fee,
+	}, nil
 }
 
 // GetTokenPrice calculates the price of given token in pool.
@@ -110,18 +149,32 @@ func (vswap *Vswap) GetTokenPrice(opts *bind.CallOpts, pool, token common.Addres
 //
 // Note, it returns 0 if pool balance of any token is 0.
 func (vswap *Vswap) GetTokenPriceUSDT(opts *bind.CallOpts, pool, token common.Address) (decimal.Decimal, error) {
-	if token == vswap.swappi.addresses.USDT {
-		return decimal.NewFromInt(1), nil
+	price, _, err := vswap.GetTokenPriceUSDTWithRoute(opts, pool, token)
+	return price, err
+}
+
+// GetTokenPriceUSDTWithRoute is the same as GetTokenPriceUSDT, but also returns the token route used to calculate
+// the price, e.g. [token, WCFX, USDT].
+func (vswap *Vswap) GetTokenPriceUSDTWithRoute(opts *bind.CallOpts, pool, token common.Address) (decimal.Decimal, []common.Address, error) {
+	usdt, wcfx := vswap.swappi.addresses.USDT, vswap.swappi.addresses.WCFX
+
+	if token == usdt {
+		return decimal.NewFromInt(1), []common.Address{token}, nil
 	}
 
-	if token == vswap.swappi.addresses.WCFX {
-		return vswap.GetTokenPrice(opts, vswap.wcfxUsdtPool, token)
+	if token == wcfx {
+		price, err := vswap.GetTokenPrice(opts, vswap.wcfxUsdtPool, token)
+		if err != nil {
+			return decimal.Zero, nil, err
+		}
+
+		return price, []common.Address{wcfx, usdt}, nil
 	}
 
 	// get pool info
 	info, err := vswap.GetPoolInfo(pool)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to get pool info")
+		return decimal.Zero, nil, errors.WithMessage(err, "Failed to get pool info")
 	}
 
 	var other common.Address
@@ -131,71 +184,88 @@ func (vswap *Vswap) GetTokenPriceUSDT(opts *bind.CallOpts, pool, token common.Ad
 	case info.Token1.Address:
 		other = info.Token0.Address
 	default:
-		return decimal.Zero, errors.Errorf("Token not found in pool %v", info)
+		return decimal.Zero, nil, errors.Errorf("Token not found in pool %v", info)
 	}
 
 	// token/usdt
-	if other == vswap.swappi.addresses.USDT {
-		return vswap.GetTokenPrice(opts, pool, token)
+	if other == usdt {
+		price, err := vswap.GetTokenPrice(opts, pool, token)
+		if err != nil {
+			return decimal.Zero, nil, err
+		}
+
+		return price, []common.Address{token, usdt}, nil
 	}
 
-	if other != vswap.swappi.addresses.WCFX {
-		return decimal.Zero, ErrVswapPoolNotFound
+	if other != wcfx {
+		return decimal.Zero, nil, ErrVswapPoolNotFound
 	}
 
 	// token/wcfx/usdt
+	route := []common.Address{token, wcfx, usdt}
+
 	wcfxPrice, err := vswap.GetTokenPrice(opts, pool, token)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to calculate token price by token/WCFX")
+		return decimal.Zero, nil, errors.WithMessage(err, "Failed to calculate token price by token/WCFX")
 	}
 
 	if wcfxPrice.IsZero() {
-		return decimal.Zero, nil
+		return decimal.Zero, route, nil
 	}
 
-	usdtPrice, err := vswap.GetTokenPrice(opts, vswap.wcfxUsdtPool, vswap.swappi.addresses.WCFX)
+	usdtPrice, err := vswap.GetTokenPrice(opts, vswap.wcfxUsdtPool, wcfx)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to calcuate WCFX price by WCFX/USDT")
+		return decimal.Zero, nil, errors.WithMessage(err, "Failed to calcuate WCFX price by WCFX/USDT")
 	}
 
-	return wcfxPrice.Mul(usdtPrice), nil
+	return wcfxPrice.Mul(usdtPrice), route, nil
 }
 
+// GetPoolTVL calculates the TVL of given pool in USDT.
 func (vswap *Vswap) GetPoolTVL(opts *bind.CallOpts, pool common.Address) (decimal.Decimal, error) {
+	tvl0, tvl1, err := vswap.GetPoolTokenTVL(opts, pool)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return tvl0.Add(tvl1), nil
+}
+
+// GetPoolTokenTVL calculates the TVL of token0 and token1 in given pool in USDT.
+//
+// Note, it returns 0 if pool balance of any token is 0.
+func (vswap *Vswap) GetPoolTokenTVL(opts *bind.CallOpts, pool common.Address) (decimal.Decimal, decimal.Decimal, error) {
 	info, err := vswap.GetPoolInfo(pool)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to get pool info")
+		return decimal.Zero, decimal.Zero, errors.WithMessage(err, "Failed to get pool info")
 	}
 
 	// get balances
 	balance0, err := vswap.swappi.erc20.GetBalance(opts, info.Token0.Address, pool)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to get pool balance of token0")
+		return decimal.Zero, decimal.Zero, errors.WithMessage(err, "Failed to get pool balance of token0")
 	}
 
 	balance1, err := vswap.swappi.erc20.GetBalance(opts, info.Token1.Address, pool)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to get pool balance of token1")
+		return decimal.Zero, decimal.Zero, errors.WithMessage(err, "Failed to get pool balance of token1")
 	}
 
 	if balance0.IsZero() || balance1.IsZero() {
-		return decimal.Zero, nil
+		return decimal.Zero, decimal.Zero, nil
 	}
 
 	// get prices
 	price0, err := vswap.GetTokenPriceUSDT(opts, pool, info.Token0.Address)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to get price of token0")
+		return decimal.Zero, decimal.Zero, errors.WithMessage(err, "Failed to get price of token0")
 	}
 
 	price1, err := vswap.GetTokenPriceUSDT(opts, pool, info.Token1.Address)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to get price of token1")
+		return decimal.Zero, decimal.Zero, errors.WithMessage(err, "Failed to get price of token1")
 	}
 
 	// calculate TVL
-	tvl0 := balance0.Mul(price0)
-	tvl1 := balance1.Mul(price1)
-
-	return tvl0.Add(tvl1), nil
+	return balance0.Mul(price0), balance1.Mul(price1), nil
 }
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/v3-Swampy/points-service/cmd/util"
+	"github.com/v3-Swampy/points-service/service"
+)
+
+// envAuditOperator is the environment variable of default operator for manual changes.
+const envAuditOperator = "PS_OPERATOR"
+
+type auditParams struct {
+	Operator string // operator identity
+	Reason   string // reason of manual change
+}
+
+type auditListParams struct {
+	Operator string
+	Action   string
+	Target   string
+	From     string // RFC3339 time
+	To       string // RFC3339 time
+	Offset   int
+	Limit    int
+}
+
+var (
+	operatorParams auditParams
+	auditFilter    auditListParams
+
+	auditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Audit log utility toolset",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	listAuditCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List audit logs of manual changes",
+		Run:   listAuditLogs,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.AddCommand(listAuditCmd)
+	hookAuditListParams(listAuditCmd)
+}
+
+func listAuditLogs(cmd *cobra.Command, args []string) {
+	storeCtx := util.MustInitStoreContext()
+	defer storeCtx.Close()
+
+	filter, err := validateAuditListParams()
+	if err != nil {
+		logrus.WithError(err).Info("Invalid command config")
+		return
+	}
+
+	logs, err := storeCtx.AuditService.List(filter)
+	if err != nil {
+		logrus.WithError(err).Info("Failed to list audit logs")
+		return
+	}
+
+	if len(logs) == 0 {
+		logrus.Info("No audit logs found")
+		return
+	}
+
+	logrus.WithField("total", len(logs)).Info("Audit logs loaded:")
+	for _, log := range logs {
+		logrus.WithFields(logrus.Fields{
+			"operator":  log.Operator,
+			"action":    log.Action,
+			"target":    log.Target,
+			"reason":    log.Reason,
+			"params":    log.Params,
+			"before":    log.Before,
+			"after":     log.After,
+			"createdAt": log.CreatedAt.Format(time.RFC3339),
+		}).Info("Audit #", log.ID)
+	}
+}
+
+func validateAuditListParams() (service.AuditLogFilter, error) {
+	filter := service.AuditLogFilter{
+		Operator: auditFilter.Operator,
+		Action:   auditFilter.Action,
+		Target:   auditFilter.Target,
+		Offset:   auditFilter.Offset,
+		Limit:    auditFilter.Limit,
+	}
+
+	var err error
+
+	if len(auditFilter.From) > 0 {
+		if filter.From, err = time.Parse(time.RFC3339, auditFilter.From); err != nil {
+			return filter, errors.WithMessagef(err, "Invalid from time %v", auditFilter.From)
+		}
+	}
+
+	if len(auditFilter.To) > 0 {
+		if filter.To, err = time.Parse(time.RFC3339, auditFilter.To); err != nil {
+			return filter, errors.WithMessagef(err, "Invalid to time %v", auditFilter.To)
+		}
+	}
+
+	if filter.Offset < 0 || filter.Limit < 0 {
+		return filter, errors.New("Offset and limit should not be negative")
+	}
+
+	return filter, nil
+}
+
+func hookAuditListParams(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&auditFilter.Operator, "operator", "", "filter by operator")
+	cmd.Flags().StringVar(&auditFilter.Action, "action", "", "filter by action, e.g. userpoints.update")
+	cmd.Flags().StringVar(&auditFilter.Target, "target", "", "filter by user or pool address")
+	cmd.Flags().StringVar(&auditFilter.From, "from", "", "filter by time (inclusive) in RFC3339 format")
+	cmd.Flags().StringVar(&auditFilter.To, "to", "", "filter by time (exclusive) in RFC3339 format")
+	cmd.Flags().IntVar(&auditFilter.Offset, "offset", 0, "number of logs to skip")
+	cmd.Flags().IntVar(&auditFilter.Limit, "limit", 100, "maximum number of logs, 0 for unlimited")
+}
+
+// validateAuditParams returns the operator and reason of manual change, where operator defaults to the
+// environment variable PS_OPERATOR if not specified.
+func validateAuditParams() (service.AuditOperator, error) {
+	op := service.AuditOperator{
+		Operator: strings.TrimSpace(operatorParams.Operator),
+		Reason:   strings.TrimSpace(operatorParams.Reason),
+	}
+
+	if len(op.Operator) == 0 {
+		return op, errors.Errorf("Operator is required by --operator or environment variable %v", envAuditOperator)
+	}
+
+	if len(op.Reason) == 0 {
+		return op, errors.New("Reason is required by --reason")
+	}
+
+	return op, nil
+}
+
+// hookAuditParams hooks the operator and reason flags for manual changes.
+func hookAuditParams(cmd *cobra.Command) {
+	cmd.Flags().StringVar(
+		&operatorParams.Operator, "operator", os.Getenv(envAuditOperator), "operator identity, defaults to env "+envAuditOperator,
+	)
+
+	cmd.Flags().StringVar(
+		&operatorParams.Reason, "reason", "", "reason of manual change",
+	)
+	cmd.MarkFlagRequired("reason")
+}
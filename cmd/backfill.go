@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/Conflux-Chain/go-conflux-util/viper"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/v3-Swampy/points-service/cmd/util"
+	"github.com/v3-Swampy/points-service/migration"
+	"github.com/v3-Swampy/points-service/service"
+	"github.com/v3-Swampy/points-service/sync/parsing"
+)
+
+type backfillParams struct {
+	From    int64 // timestamp of the first snapshot to backfill
+	To      int64 // timestamp of the last snapshot to backfill
+	Workers int   // number of snapshots to poll and emit concurrently
+
+	ReplaySkipped bool // replay events of pools skipped in applied snapshots, e.g. quarantined
+}
+
+// maxReplaySkipped is the maximum number of skipped snapshots of pools to replay at a time.
+const maxReplaySkipped = 10000
+
+var (
+	fillParams backfillParams
+
+	backfillCmd = &cobra.Command{
+		Use:   "backfill",
+		Short: "Backfill historical snapshots in parallel",
+		Long: "Poll and emit historical snapshots concurrently, and apply them in order of timestamp. " +
+			"Backfill resumes from the last applied snapshot, and service continues from where backfill ends. " +
+			"Note, service should be stopped during backfill, otherwise backfill refuses to start.",
+		Run: backfill,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(backfillCmd)
+
+	backfillCmd.Flags().Int64Var(&fillParams.From, "from", 0, "timestamp of the first snapshot, defaults to resume from the last applied snapshot")
+	backfillCmd.Flags().Int64Var(&fillParams.To, "to", 0, "timestamp of the last snapshot, defaults to the latest snapshot")
+	backfillCmd.Flags().IntVar(&fillParams.Workers, "workers", 8, "number of snapshots to poll and emit concurrently")
+	backfillCmd.Flags().BoolVar(&fillParams.ReplaySkipped, "replay-skipped", false, "replay events of pools skipped in applied snapshots, e.g. quarantined, instead of backfilling snapshots")
+}
+
+func backfill(cmd *cobra.Command, args []string) {
+	if err := validateBackfillParams(); err != nil {
+		logrus.WithError(err).Info("Invalid command config")
+		return
+	}
+
+	// init database
+	store := util.MustOpenStoreFromViper()
+	defer store.Close()
+	migration.NewDefaultMigrator(store.DB).MustBeLatest()
+
+	// init blockchain
+	bc := util.MustInitBlockchainContext(store)
+	defer bc.Close()
+
+	var syncConfig parsing.Config
+	viper.MustUnmarshalKey("sync", &syncConfig)
+
+	services := service.NewServices(store, bc.Vswap, util.MustLoadPointsConfig(), syncConfig.Emitter.Concurrency)
+
+	// applied snapshots are committed, so backfill could be interrupted and resumed later
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	// release sync lease before exit
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	mustAcquireSyncLease(ctx, &wg, services.Lease)
+
+	poller := mustCreatePoller(services, bc, syncConfig)
+	defer poller.Close()
+
+	emitter := parsing.NewEmitter(bc.Vswap, syncConfig.Emitter)
+	defer emitter.Close()
+
+	backfiller := parsing.NewBackfiller(poller, emitter, services.Stat, parsing.BackfillOption{
+		Workers: fillParams.Workers,
+	})
+
+	if fillParams.ReplaySkipped {
+		replaySkipped(ctx, backfiller, services)
+		return
+	}
+
+	applied, err := backfiller.Run(ctx, fillParams.From, fillParams.To)
+	if err != nil {
+		logrus.WithError(err).WithField("applied", applied).Info("Failed to backfill snapshots")
+		return
+	}
+
+	logrus.WithField("applied", applied).Info("Succeed to backfill snapshots")
+}
+
+func replaySkipped(ctx context.Context, backfiller *parsing.Backfiller, services service.Services) {
+	skipped, err := services.Skipped.ListPending(maxReplaySkipped)
+	if err != nil {
+		logrus.WithError(err).Info("Failed to get skipped snapshots")
+		return
+	}
+
+	replayed, err := backfiller.Replay(ctx, skipped, services.Stat)
+	if err != nil {
+		logrus.WithError(err).WithField("replayed", replayed).Info("Failed to replay skipped snapshots")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"pending":  len(skipped),
+		"replayed": replayed,
+	}).Info("Succeed to replay skipped snapshots")
+}
+
+func validateBackfillParams() error {
+	if fillParams.From < 0 || fillParams.To < 0 {
+		return errors.New("--from and --to should not be negative")
+	}
+
+	if fillParams.To > 0 && fillParams.From > fillParams.To {
+		return errors.New("--from should not be greater than --to")
+	}
+
+	if fillParams.Workers <= 0 {
+		return errors.New("--workers should be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/v3-Swampy/points-service/blockchain"
+	"github.com/v3-Swampy/points-service/cmd/util"
+)
+
+type cacheClearParams struct {
+	Tokens []string // token addresses
+	Pools  []string // pool or pair addresses
+	All    bool     // clear all cached metadata
+}
+
+var (
+	clearParams cacheClearParams
+
+	cacheCmd = &cobra.Command{
+		Use:   "cache",
+		Short: "On-chain metadata cache utility toolset",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	clearCacheCmd = &cobra.Command{
+		Use:   "clear",
+		Short: "Invalidate cached on-chain metadata in database",
+		Long: "Invalidate cached on-chain metadata in database, which will be queried from blockchain again. " +
+			"Note, running service reloads invalidated metadata once expired in memory.",
+		Run: clearCache,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+
+	cacheCmd.AddCommand(clearCacheCmd)
+	clearCacheCmd.Flags().StringSliceVar(&clearParams.Tokens, "token", nil, "token addresses to invalidate name, symbol and decimals")
+	clearCacheCmd.Flags().StringSliceVar(&clearParams.Pools, "pool", nil, "pool or pair addresses to invalidate tokens and fee")
+	clearCacheCmd.Flags().BoolVar(&clearParams.All, "all", false, "invalidate all cached metadata")
+}
+
+func clearCache(cmd *cobra.Command, args []string) {
+	storeCtx := util.MustInitStoreContext()
+	defer storeCtx.Close()
+
+	tokens, pools, err := validateCacheClearParams()
+	if err != nil {
+		logrus.WithError(err).Info("Invalid command config")
+		return
+	}
+
+	targets := map[string][]string{
+		blockchain.CacheNamespaceToken: tokens,
+		blockchain.CacheNamespacePair:  pools,
+		blockchain.CacheNamespacePool:  pools,
+	}
+
+	for _, namespace := range []string{blockchain.CacheNamespaceToken, blockchain.CacheNamespacePair, blockchain.CacheNamespacePool} {
+		keys := targets[namespace]
+		if !clearParams.All && len(keys) == 0 {
+			continue
+		}
+
+		count, err := storeCtx.CacheService.Clear(namespace, keys...)
+		if err != nil {
+			logrus.WithError(err).WithField("namespace", namespace).Info("Failed to clear cache")
+			return
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"namespace": namespace,
+			"deleted":   count,
+		}).Info("Succeed to clear cache")
+	}
+}
+
+func validateCacheClearParams() (tokens, pools []string, err error) {
+	if clearParams.All {
+		if len(clearParams.Tokens) > 0 || len(clearParams.Pools) > 0 {
+			return nil, nil, errors.New("--all could not be specified along with --token or --pool")
+		}
+
+		return nil, nil, nil
+	}
+
+	if len(clearParams.Tokens) == 0 && len(clearParams.Pools) == 0 {
+		return nil, nil, errors.New("At least one of --token, --pool or --all is required")
+	}
+
+	if tokens, err = normalizeAddresses(clearParams.Tokens, "token"); err != nil {
+		return nil, nil, err
+	}
+
+	if pools, err = normalizeAddresses(clearParams.Pools, "pool"); err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, pools, nil
+}
+
+// normalizeAddresses validates and converts addresses into checksum format, which is the cache key in database.
+func normalizeAddresses(addresses []string, kind string) ([]string, error) {
+	result := make([]string, 0, len(addresses))
+	for _, v := range addresses {
+		if !common.IsHexAddress(v) {
+			return nil, errors.Errorf("Invalid hex address of %v %v", kind, v)
+		}
+
+		result = append(result, common.HexToAddress(v).String())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/v3-Swampy/points-service/cmd/util"
+	"github.com/v3-Swampy/points-service/model"
+	"github.com/v3-Swampy/points-service/service"
+)
+
+type merkleExportParams struct {
+	Season    string          // season id
+	Rate      decimal.Decimal // tokens per point
+	RateParam string
+	Decimals  uint8  // token decimals
+	Output    string // output file path
+	Overwrite bool   // overwrite the exported season
+}
+
+type merkleExportFile struct {
+	*model.MerkleDistribution
+	Claims []service.MerkleClaim `json:"claims"`
+}
+
+var (
+	merkleParams merkleExportParams
+
+	exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export utility toolset",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	exportMerkleCmd = &cobra.Command{
+		Use:   "merkle",
+		Short: "Export Merkle root and proofs of user points for airdrop distribution",
+		Run:   exportMerkle,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.AddCommand(exportMerkleCmd)
+	hookMerkleExportParams(exportMerkleCmd)
+}
+
+func exportMerkle(cmd *cobra.Command, args []string) {
+	storeCtx := util.MustInitStoreContext()
+	defer storeCtx.Close()
+
+	if err := validateMerkleExportParams(); err != nil {
+		logrus.WithError(err).Info("Invalid command config")
+		return
+	}
+
+	distribution, claims, err := storeCtx.MerkleService.Build(merkleParams.Season, merkleParams.Rate, merkleParams.Decimals)
+	if err != nil {
+		logrus.WithError(err).Info("Failed to build Merkle tree")
+		return
+	}
+
+	if err = storeCtx.MerkleService.Save(distribution, claims, merkleParams.Overwrite); err != nil {
+		logrus.WithError(err).Info("Failed to save Merkle distribution")
+		return
+	}
+
+	data, err := json.MarshalIndent(merkleExportFile{distribution, claims}, "", "  ")
+	if err != nil {
+		logrus.WithError(err).Info("Failed to marshal Merkle distribution")
+		return
+	}
+
+	if err = os.WriteFile(merkleParams.Output, data, 0644); err != nil {
+		logrus.WithError(err).Info("Failed to write Merkle distribution file")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"season":   distribution.Season,
+		"root":     distribution.Root,
+		"total":    distribution.Total,
+		"accounts": distribution.Accounts,
+		"output":   merkleParams.Output,
+	}).Info("Succeed to export Merkle distribution")
+}
+
+func validateMerkleExportParams() error {
+	if len(merkleParams.Season) == 0 {
+		return errors.New("Season not specified")
+	}
+
+	rate, err := decimal.NewFromString(merkleParams.RateParam)
+	if err != nil {
+		return errors.Errorf("Invalid rate %v", merkleParams.RateParam)
+	}
+
+	if !rate.IsPositive() {
+		return errors.Errorf("Invalid rate %v, which should be positive", merkleParams.RateParam)
+	}
+
+	merkleParams.Rate = rate
+
+	if len(merkleParams.Output) == 0 {
+		merkleParams.Output = fmt.Sprintf("merkle-%v.json", merkleParams.Season)
+	}
+
+	return nil
+}
+
+func hookMerkleExportParams(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(
+		&merkleParams.Season, "season", "s", "", "season id",
+	)
+	cmd.MarkFlagRequired("season")
+
+	cmd.Flags().StringVarP(
+		&merkleParams.RateParam, "rate", "r", "", "tokens per point",
+	)
+	cmd.MarkFlagRequired("rate")
+
+	cmd.Flags().Uint8VarP(
+		&merkleParams.Decimals, "decimals", "d", 18, "token decimals",
+	)
+
+	cmd.Flags().StringVarP(
+		&merkleParams.Output, "output", "o", "", "output file path, merkle-<season>.json by default",
+	)
+
+	cmd.Flags().BoolVar(
+		&merkleParams.Overwrite, "overwrite", false, "overwrite the exported season",
+	)
+}
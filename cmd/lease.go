@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/cmd"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/v3-Swampy/points-service/service"
+)
+
+// syncLeaseTTL is the TTL of sync lease, which is renewed every third of TTL.
+const syncLeaseTTL = 30 * time.Second
+
+// mustAcquireSyncLease acquires the sync lease exclusively, so that snapshots will not be applied concurrently by
+// the sync of service and backfill command. Then, lease is renewed in background, and released once context done.
+//
+// Note, it should be called before poller created, which resumes from the last applied snapshot.
+func mustAcquireSyncLease(ctx context.Context, wg *sync.WaitGroup, lease *service.LeaseService) {
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%v:%v", hostname, os.Getpid())
+
+	err := lease.Acquire(service.LeaseSync, owner, syncLeaseTTL)
+	cmd.FatalIfErr(err, "Failed to acquire sync lease, e.g. service or backfill is running")
+
+	logrus.WithField("owner", owner).Debug("Sync lease acquired")
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(syncLeaseTTL / 3)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				if err := lease.Release(service.LeaseSync, owner); err != nil {
+					logrus.WithError(err).Warn("Failed to release sync lease")
+				}
+
+				return
+			case <-ticker.C:
+				err := lease.Acquire(service.LeaseSync, owner, syncLeaseTTL)
+				if errors.Is(err, service.ErrLeaseHeld) {
+					logrus.WithError(err).Fatal("Sync lease taken over by others")
+				}
+
+				if err != nil {
+					logrus.WithError(err).Warn("Failed to renew sync lease")
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/v3-Swampy/points-service/cmd/util"
+	"github.com/v3-Swampy/points-service/migration"
+)
+
+type migrateParams struct {
+	To    uint32 // target version to migrate up
+	Steps int    // number of migrations to roll back
+	Force bool   // force to roll back destructive migrations, e.g. baseline
+}
+
+var (
+	migrationParams migrateParams
+
+	migrateCmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "Database schema migration toolset",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	migrateUpCmd = &cobra.Command{
+		Use:   "up",
+		Short: "Apply pending migrations",
+		Run:   migrateUp,
+	}
+
+	migrateDownCmd = &cobra.Command{
+		Use:   "down",
+		Short: "Roll back applied migrations",
+		Run:   migrateDown,
+	}
+
+	migrateStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show status of all migrations",
+		Run:   migrateStatus,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateUpCmd.Flags().Uint32Var(&migrationParams.To, "to", 0, "target version, defaults to the latest version")
+
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateDownCmd.Flags().IntVar(&migrationParams.Steps, "steps", 1, "number of migrations to roll back")
+	migrateDownCmd.Flags().BoolVar(&migrationParams.Force, "force", false, "force to roll back destructive migrations, which drops all data of baseline tables")
+
+	migrateCmd.AddCommand(migrateStatusCmd)
+}
+
+func migrateUp(cmd *cobra.Command, args []string) {
+	store := util.MustOpenStoreFromViper()
+	defer store.Close()
+
+	count, err := migration.NewDefaultMigrator(store.DB).Up(migrationParams.To)
+	if err != nil {
+		logrus.WithError(err).WithField("applied", count).Info("Failed to apply migrations")
+		return
+	}
+
+	logrus.WithField("applied", count).Info("Succeed to apply migrations")
+}
+
+func migrateDown(cmd *cobra.Command, args []string) {
+	if migrationParams.Steps <= 0 {
+		logrus.Info("Invalid command config: --steps should be positive")
+		return
+	}
+
+	store := util.MustOpenStoreFromViper()
+	defer store.Close()
+
+	count, err := migration.NewDefaultMigrator(store.DB).Down(migrationParams.Steps, migrationParams.Force)
+	if err != nil {
+		logrus.WithError(err).WithField("rolledBack", count).Info("Failed to roll back migrations")
+		return
+	}
+
+	logrus.WithField("rolledBack", count).Info("Succeed to roll back migrations")
+}
+
+func migrateStatus(cmd *cobra.Command, args []string) {
+	store := util.MustOpenStoreFromViper()
+	defer store.Close()
+
+	migrator := migration.NewDefaultMigrator(store.DB)
+
+	status, err := migrator.Status()
+	if err != nil {
+		logrus.WithError(err).Info("Failed to get migration status")
+		return
+	}
+
+	current, err := migrator.Current()
+	if err != nil {
+		logrus.WithError(err).Info("Failed to get current migration version")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"current": current,
+		"latest":  migrator.Latest(),
+	}).Info("Migration status loaded:")
+
+	for _, v := range status {
+		fields := logrus.Fields{
+			"description": v.Description,
+			"applied":     v.Applied,
+		}
+
+		if v.Applied {
+			fields["appliedAt"] = v.AppliedAt.Format(time.RFC3339)
+		}
+
+		logrus.WithFields(fields).Info("Migration #", v.Version)
+	}
+}
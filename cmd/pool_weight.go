@@ -60,9 +60,11 @@ func init() {
 
 	poolWeightCmd.AddCommand(addPoolWeightCmd)
 	hookPoolWeightParams(addPoolWeightCmd, true, true)
+	hookAuditParams(addPoolWeightCmd)
 
 	poolWeightCmd.AddCommand(updatePoolWeightCmd)
 	hookPoolWeightParams(updatePoolWeightCmd, true, true)
+	hookAuditParams(updatePoolWeightCmd)
 
 	poolWeightCmd.AddCommand(getPoolWeightCmd)
 	hookPoolWeightParams(getPoolWeightCmd, false, false)
@@ -92,8 +94,14 @@ func upsertPoolWeight() {
 		return
 	}
 
-	if err := storeCtx.PoolParamService.
-		Upsert(weightParams.Address, weightParams.TradeWeight, weightParams.LiquidityWeight); err != nil {
+	op, err := validateAuditParams()
+	if err != nil {
+		logrus.WithError(err).Info("Invalid command config")
+		return
+	}
+
+	if err := storeCtx.AuditService.
+		UpsertPoolWeight(op, weightParams.Address, weightParams.TradeWeight, weightParams.LiquidityWeight); err != nil {
 		logrus.WithError(err).Info("Failed to upsert pool weight values")
 		return
 	}
@@ -7,15 +7,15 @@ import (
 	"github.com/Conflux-Chain/go-conflux-util/cmd"
 	"github.com/Conflux-Chain/go-conflux-util/config"
 	"github.com/Conflux-Chain/go-conflux-util/log"
-	"github.com/Conflux-Chain/go-conflux-util/store"
 	"github.com/Conflux-Chain/go-conflux-util/viper"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/openweb3/web3go"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/v3-Swampy/points-service/api"
 	"github.com/v3-Swampy/points-service/blockchain"
-	"github.com/v3-Swampy/points-service/model"
+	"github.com/v3-Swampy/points-service/blockchain/scan"
+	"github.com/v3-Swampy/points-service/cmd/util"
+	"github.com/v3-Swampy/points-service/migration"
 	"github.com/v3-Swampy/points-service/service"
 	"github.com/v3-Swampy/points-service/sync/parsing"
 )
@@ -39,55 +39,32 @@ func start(*cobra.Command, []string) {
 	ctx, cancel := context.WithCancel(context.Background())
 	var wg sync.WaitGroup
 
-	// init blockchain
-	var blockchainConfig blockchain.Config
-	viper.MustUnmarshalKey("blockchain", &blockchainConfig)
-	clientOption := web3go.ClientOption{
-		Option: blockchainConfig.Option,
-	}
-	client, err := web3go.NewClientWithOption(blockchainConfig.URL, clientOption)
-	cmd.FatalIfErr(err, "Failed to create blockchain client")
-	defer client.Close()
-
-	// init swappi
-	call, _ := client.ToClientForContract()
-	erc20 := blockchain.NewERC20(call)
-	swappi := blockchain.NewSwappi(call, erc20, blockchainConfig.Swappi.ToAddresses())
-	vswap := blockchain.NewVswap(swappi, common.HexToAddress(blockchainConfig.Vswap.WcfxUsdtPool))
-
 	// init database
-	storeConfig := store.MustNewConfigFromViper()
-	db := storeConfig.MustOpenOrCreate(model.Tables...)
-	store := store.NewStore(db)
+	store := util.MustOpenStoreFromViper()
+	migration.NewDefaultMigrator(store.DB).MustBeLatest()
 
-	// init services
-	services := service.NewServices(store, vswap)
+	// init blockchain
+	bc := util.MustInitBlockchainContext(store)
+	defer bc.Close()
 
-	var pools []common.Address
-	for _, v := range services.PoolParam.MustListPoolAddresses() {
-		pools = append(pools, common.HexToAddress(v))
-	}
+	var syncConfig parsing.Config
+	viper.MustUnmarshalKey("sync", &syncConfig)
 
-	lastStatTimestamp, err := services.Config.GetLastStatPointsTime()
-	cmd.FatalIfErr(err, "Failed to get last stat points time")
+	// init services
+	services := service.NewServices(store, bc.Vswap, util.MustLoadPointsConfig(), syncConfig.Emitter.Concurrency)
 
 	// init poller/emitter/batcher
-	var syncConfig parsing.Config
-	viper.MustUnmarshalKey("sync", &syncConfig)
+	err := parsing.InitAlert(syncConfig.Alert)
+	cmd.FatalIfErr(err, "Failed to init sync alert")
 
-	poller, err := parsing.NewPoller(
-		syncConfig.Poller.RpcUrl,
-		syncConfig.Poller.ScanUrl,
-		lastStatTimestamp,
-		pools,
-		syncConfig.Poller.Option,
-	)
-	cmd.FatalIfErr(err, "Failed to create poller")
+	mustAcquireSyncLease(ctx, &wg, services.Lease)
+
+	poller := mustCreatePoller(services, bc, syncConfig)
 	defer poller.Close()
 	wg.Add(1)
 	go poller.Run(ctx, &wg)
 
-	emitter := parsing.NewEmitter(vswap, swappi, syncConfig.Emitter)
+	emitter := parsing.NewEmitter(bc.Vswap, syncConfig.Emitter)
 	defer emitter.Close()
 	wg.Add(1)
 	go emitter.Run(ctx, &wg, poller.Ch())
@@ -104,6 +81,37 @@ func start(*cobra.Command, []string) {
 	cmd.GracefulShutdown(&wg, cancel)
 }
 
+// mustCreatePoller creates poller of tracked pools, which resumes from the block range of the last applied snapshot.
+func mustCreatePoller(services service.Services, bc util.BlockchainContext, config parsing.Config) *parsing.Poller {
+	var pools []common.Address
+	for _, v := range services.PoolParam.MustListPoolAddresses() {
+		pools = append(pools, common.HexToAddress(v))
+	}
+
+	last, ok, err := services.Checkpoint.Get(service.CheckpointStageStat)
+	cmd.FatalIfErr(err, "Failed to get stat checkpoint")
+	if !ok {
+		// deployed before checkpoint introduced
+		last.Timestamp, err = services.Config.GetLastStatPointsTime()
+		cmd.FatalIfErr(err, "Failed to get last stat points time")
+	}
+
+	// resolve block number by time via scan, and fall back to blockchain RPC
+	resolvers := map[string]blockchain.BlockResolver{
+		blockchain.BlockResolverRPC: blockchain.NewRpcBlockResolver(bc.Client, config.Poller.BlockResolver),
+	}
+	if len(config.Poller.ScanUrl) > 0 {
+		resolvers[blockchain.BlockResolverScan] = scan.NewApi(config.Poller.ScanUrl, config.Poller.Option.Scan)
+	}
+	resolver, err := blockchain.NewFallbackBlockResolver(config.Poller.BlockResolver, resolvers)
+	cmd.FatalIfErr(err, "Failed to create block resolver")
+
+	poller, err := parsing.NewPoller(config.ParserUrls(), resolver, last, pools, config.Poller.Option)
+	cmd.FatalIfErr(err, "Failed to create poller")
+
+	return poller
+}
+
 // Execute is the command line entrypoint.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/v3-Swampy/points-service/cmd/util"
+	"github.com/v3-Swampy/points-service/service"
+)
+
+type tokenOverrideParams struct {
+	Address       string // token address
+	LogoURL       string // logo URL, empty to clear
+	DisplaySymbol string // display symbol, empty to clear
+}
+
+var (
+	overrideParams tokenOverrideParams
+
+	tokenCmd = &cobra.Command{
+		Use:   "token",
+		Short: "Token registry utility toolset",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+
+	listTokenCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List all tokens of tracked pools",
+		Run:   listTokens,
+	}
+
+	overrideTokenCmd = &cobra.Command{
+		Use:   "override",
+		Short: "Override logo URL and display symbol of token",
+		Run:   overrideToken,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(tokenCmd)
+
+	tokenCmd.AddCommand(listTokenCmd)
+
+	tokenCmd.AddCommand(overrideTokenCmd)
+	hookTokenOverrideParams(overrideTokenCmd)
+	hookAuditParams(overrideTokenCmd)
+}
+
+func listTokens(cmd *cobra.Command, args []string) {
+	storeCtx := util.MustInitStoreContext()
+	defer storeCtx.Close()
+
+	tokens, err := storeCtx.TokenService.List()
+	if err != nil {
+		logrus.WithError(err).Info("Failed to list tokens")
+		return
+	}
+
+	if len(tokens) == 0 {
+		logrus.Info("No tokens found")
+		return
+	}
+
+	logrus.WithField("total", len(tokens)).Info("Tokens loaded:")
+	for i, v := range tokens {
+		logrus.WithFields(logrus.Fields{
+			"address":       v.Address,
+			"name":          v.Name,
+			"symbol":        v.Symbol,
+			"decimals":      v.Decimals,
+			"logoUrl":       v.LogoURL,
+			"displaySymbol": v.DisplaySymbol,
+			"price":         v.Price,
+			"tvl":           v.Tvl,
+		}).Info("Token #", i)
+	}
+}
+
+func overrideToken(cmd *cobra.Command, args []string) {
+	storeCtx := util.MustInitStoreContext()
+	defer storeCtx.Close()
+
+	if err := validateTokenOverrideParams(); err != nil {
+		logrus.WithError(err).Info("Invalid command config")
+		return
+	}
+
+	op, err := validateAuditParams()
+	if err != nil {
+		logrus.WithError(err).Info("Invalid command config")
+		return
+	}
+
+	if err := storeCtx.AuditService.
+		SetTokenOverrides(op, overrideParams.Address, overrideParams.LogoURL, overrideParams.DisplaySymbol); err != nil {
+		logrus.WithError(err).Info("Failed to override token")
+		return
+	}
+
+	logrus.Info("Succeed to override token")
+}
+
+func validateTokenOverrideParams() error {
+	if !common.IsHexAddress(overrideParams.Address) {
+		return errors.Errorf("Invalid hex address of token %v", overrideParams.Address)
+	}
+
+	overrideParams.Address = common.HexToAddress(overrideParams.Address).String()
+	overrideParams.LogoURL = strings.TrimSpace(overrideParams.LogoURL)
+	overrideParams.DisplaySymbol = strings.TrimSpace(overrideParams.DisplaySymbol)
+
+	return service.ValidateTokenOverrides(overrideParams.LogoURL, overrideParams.DisplaySymbol)
+}
+
+func hookTokenOverrideParams(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&overrideParams.Address, "token", "", "token address")
+	cmd.MarkFlagRequired("token")
+
+	cmd.Flags().StringVar(&overrideParams.LogoURL, "logo", "", "logo URL, empty to clear")
+	cmd.Flags().StringVar(&overrideParams.DisplaySymbol, "symbol", "", "display symbol, empty to clear")
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"regexp"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -9,13 +10,14 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/v3-Swampy/points-service/cmd/util"
+	"github.com/v3-Swampy/points-service/service"
 )
 
 type userPointsParams struct {
 	Address              string          // user address
 	TradePoints          decimal.Decimal // trade points
 	LiquidityPoints      decimal.Decimal // liquidity points
-	TradePointsParam     uint32
+	TradePointsParam     string
 	LiquidityPointsParam string
 }
 
@@ -60,12 +62,15 @@ func init() {
 
 	userPointsCmd.AddCommand(insertUserPointsCmd)
 	hookUserPointsParams(insertUserPointsCmd, true, true)
+	hookAuditParams(insertUserPointsCmd)
 
 	userPointsCmd.AddCommand(increaseUserPointsCmd)
 	hookUserPointsParams(increaseUserPointsCmd, true, true)
+	hookAuditParams(increaseUserPointsCmd)
 
 	userPointsCmd.AddCommand(decreaseUserPointsCmd)
 	hookUserPointsParams(decreaseUserPointsCmd, true, true)
+	hookAuditParams(decreaseUserPointsCmd)
 
 	userPointsCmd.AddCommand(getUserPointsCmd)
 	hookUserPointsParams(getUserPointsCmd, false, false)
@@ -75,14 +80,20 @@ func insertUserPoints(cmd *cobra.Command, args []string) {
 	storeCtx := util.MustInitStoreContext()
 	defer storeCtx.Close()
 
-	err := validateAndConvertUserPointsParams(true, true)
+	err := validateAndConvertUserPointsParams(storeCtx.PointsConfig.Precision, true, true)
 	if err != nil {
 		logrus.WithError(err).Info("Invalid command config")
 		return
 	}
 
-	if _, err = storeCtx.UserService.
-		Add(pointsParams.Address, pointsParams.TradePoints, pointsParams.LiquidityPoints); err != nil {
+	op, err := validateAuditParams()
+	if err != nil {
+		logrus.WithError(err).Info("Invalid command config")
+		return
+	}
+
+	if _, err = storeCtx.AuditService.
+		AddUser(op, pointsParams.Address, pointsParams.TradePoints, pointsParams.LiquidityPoints); err != nil {
 		logrus.WithError(err).Info("Failed to insert user points")
 		return
 	}
@@ -102,7 +113,7 @@ func deltaUpdateUserPoints(decrease bool) {
 	storeCtx := util.MustInitStoreContext()
 	defer storeCtx.Close()
 
-	err := validateAndConvertUserPointsParams(true, true)
+	err := validateAndConvertUserPointsParams(storeCtx.PointsConfig.Precision, true, true)
 	if err != nil {
 		logrus.WithError(err).Info("Invalid command config")
 		return
@@ -113,13 +124,19 @@ func deltaUpdateUserPoints(decrease bool) {
 		return
 	}
 
+	op, err := validateAuditParams()
+	if err != nil {
+		logrus.WithError(err).Info("Invalid command config")
+		return
+	}
+
 	if decrease {
 		pointsParams.TradePoints = pointsParams.TradePoints.Neg()
 		pointsParams.LiquidityPoints = pointsParams.LiquidityPoints.Neg()
 	}
 
-	if err := storeCtx.UserService.
-		DeltaUpdate(pointsParams.Address, pointsParams.TradePoints, pointsParams.LiquidityPoints); err != nil {
+	if err := storeCtx.AuditService.
+		DeltaUpdateUser(op, pointsParams.Address, pointsParams.TradePoints, pointsParams.LiquidityPoints); err != nil {
 		logrus.WithError(err).Info("Failed to update user points")
 		return
 	}
@@ -131,7 +148,7 @@ func getUserPoints(cmd *cobra.Command, args []string) {
 	storeCtx := util.MustInitStoreContext()
 	defer storeCtx.Close()
 
-	err := validateAndConvertUserPointsParams(false, false)
+	err := validateAndConvertUserPointsParams(storeCtx.PointsConfig.Precision, false, false)
 	if err != nil {
 		logrus.WithError(err).Info("Invalid command config")
 		return
@@ -150,27 +167,23 @@ func getUserPoints(cmd *cobra.Command, args []string) {
 	}).Info("Succeed to get user points")
 }
 
-func validateAndConvertUserPointsParams(validateTradePoints bool, validateLiquidityPoints bool) error {
+func validateAndConvertUserPointsParams(precision service.PrecisionConfig, validateTradePoints bool, validateLiquidityPoints bool) error {
 	if !common.IsHexAddress(pointsParams.Address) {
 		return errors.Errorf("Invalid hex address of user %v", pointsParams.Address)
 	}
 
 	if validateTradePoints {
-		pointsParams.TradePoints = decimal.NewFromInt(int64(pointsParams.TradePointsParam))
-	}
-
-	if validateLiquidityPoints {
-		matched, err := regexp.MatchString(`^(0|[1-9]\d*)(\.\d)?$`, pointsParams.LiquidityPointsParam)
+		tradePoints, err := parsePointsParam(pointsParams.TradePointsParam, precision.Trade)
 		if err != nil {
-			return errors.Errorf("Invalid liquidity points value %v", pointsParams.LiquidityPointsParam)
-		}
-		if !matched {
-			return errors.Errorf("Invalid liquidity points value %v. Only numbers are supported, with a maximum of one decimal", pointsParams.LiquidityPointsParam)
+			return errors.WithMessage(err, "Invalid trade points value")
 		}
+		pointsParams.TradePoints = tradePoints
+	}
 
-		liquidityPoints, err := decimal.NewFromString(pointsParams.LiquidityPointsParam)
+	if validateLiquidityPoints {
+		liquidityPoints, err := parsePointsParam(pointsParams.LiquidityPointsParam, precision.Liquidity)
 		if err != nil {
-			return err
+			return errors.WithMessage(err, "Invalid liquidity points value")
 		}
 		pointsParams.LiquidityPoints = liquidityPoints
 	}
@@ -178,6 +191,25 @@ func validateAndConvertUserPointsParams(validateTradePoints bool, validateLiquid
 	return nil
 }
 
+// parsePointsParam parses the non-negative points value with a maximum of given decimals.
+func parsePointsParam(value string, decimals int32) (decimal.Decimal, error) {
+	pattern := `^(0|[1-9]\d*)$`
+	if decimals > 0 {
+		pattern = fmt.Sprintf(`^(0|[1-9]\d*)(\.\d{1,%v})?$`, decimals)
+	}
+
+	matched, err := regexp.MatchString(pattern, value)
+	if err != nil {
+		return decimal.Zero, errors.Errorf("Invalid points value %v", value)
+	}
+
+	if !matched {
+		return decimal.Zero, errors.Errorf("Invalid points value %v. Only numbers are supported, with a maximum of %v decimals", value, decimals)
+	}
+
+	return decimal.NewFromString(value)
+}
+
 func hookUserPointsParams(cmd *cobra.Command, hookTrade, hookLiquidity bool) {
 	cmd.Flags().StringVarP(
 		&pointsParams.Address, "user", "u", "", "user address",
@@ -185,8 +217,8 @@ func hookUserPointsParams(cmd *cobra.Command, hookTrade, hookLiquidity bool) {
 	cmd.MarkFlagRequired("user")
 
 	if hookTrade {
-		cmd.Flags().Uint32VarP(
-			&pointsParams.TradePointsParam, "trade", "t", 0, "trade points",
+		cmd.Flags().StringVarP(
+			&pointsParams.TradePointsParam, "trade", "t", "0", "trade points",
 		)
 	}
 
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"github.com/Conflux-Chain/go-conflux-util/cmd"
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/Conflux-Chain/go-conflux-util/viper"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/openweb3/web3go"
+	"github.com/v3-Swampy/points-service/blockchain"
+	"github.com/v3-Swampy/points-service/service"
+)
+
+// BlockchainContext is the blockchain client along with the vswap contract, in which on-chain metadata is cached
+// in database.
+type BlockchainContext struct {
+	Client *web3go.Client
+	Vswap  *blockchain.Vswap
+}
+
+// MustInitBlockchainContext creates blockchain client from viper, and exits if any error occurred.
+func MustInitBlockchainContext(store *store.Store) BlockchainContext {
+	var config blockchain.Config
+	viper.MustUnmarshalKey("blockchain", &config)
+
+	clientOption := web3go.ClientOption{
+		Option: config.Option,
+	}
+	client, err := web3go.NewClientWithOption(config.URL, clientOption)
+	cmd.FatalIfErr(err, "Failed to create blockchain client")
+	blockchain.HookRPCMetrics(client.Provider(), "blockchain")
+
+	// init swappi with metadata cached in database
+	cache := blockchain.CacheOption{
+		Config: config.Cache,
+		Store:  service.NewMetadataCacheService(store),
+	}
+	client4Contract, _ := client.ToClientForContract()
+	call := blockchain.NewRateLimitedCaller(client4Contract, config.RateLimit)
+	erc20 := blockchain.NewERC20(call, cache)
+	swappi := blockchain.NewSwappi(call, erc20, config.Swappi.ToAddresses(), cache)
+	multicall, err := blockchain.NewMulticall(call, config.Multicall)
+	cmd.FatalIfErr(err, "Failed to create multicall")
+	vswap := blockchain.NewVswap(swappi, common.HexToAddress(config.Vswap.WcfxUsdtPool), multicall, cache)
+
+	return BlockchainContext{
+		Client: client,
+		Vswap:  vswap,
+	}
+}
+
+func (ctx *BlockchainContext) Close() {
+	if ctx.Client != nil {
+		ctx.Client.Close()
+	}
+}
@@ -2,27 +2,38 @@ package util
 
 import (
 	"github.com/Conflux-Chain/go-conflux-util/store"
-	"github.com/v3-Swampy/points-service/model"
+	"github.com/Conflux-Chain/go-conflux-util/viper"
+	"github.com/sirupsen/logrus"
+	"github.com/v3-Swampy/points-service/migration"
 	"github.com/v3-Swampy/points-service/service"
 )
 
 type StoreContext struct {
 	Store            *store.Store
+	PointsConfig     service.PointsConfig
 	PoolParamService *service.PoolParamService
 	UserService      *service.UserService
+	MerkleService    *service.MerkleService
+	TokenService     *service.TokenService
+	CacheService     *service.MetadataCacheService
+	AuditService     *service.AuditService
 }
 
 func MustInitStoreContext() StoreContext {
 	var ctx StoreContext
 
 	// init database
-	storeConfig := store.MustNewConfigFromViper()
-	db := storeConfig.MustOpenOrCreate(model.Tables...)
-	ctx.Store = store.NewStore(db)
+	ctx.Store = MustOpenStoreFromViper()
+	migration.NewDefaultMigrator(ctx.Store.DB).MustBeLatest()
 
 	// init services
+	ctx.PointsConfig = MustLoadPointsConfig()
 	ctx.PoolParamService = service.NewPoolParamService(ctx.Store)
-	ctx.UserService = service.NewUserService(ctx.Store)
+	ctx.UserService = service.NewUserService(ctx.Store, ctx.PointsConfig.Precision)
+	ctx.MerkleService = service.NewMerkleService(ctx.Store)
+	ctx.TokenService = service.NewTokenService(ctx.Store)
+	ctx.CacheService = service.NewMetadataCacheService(ctx.Store)
+	ctx.AuditService = service.NewAuditService(ctx.Store, ctx.UserService, ctx.PoolParamService, ctx.TokenService)
 
 	return ctx
 }
@@ -32,3 +43,15 @@ func (ctx *StoreContext) Close() {
 		ctx.Store.Close()
 	}
 }
+
+// MustLoadPointsConfig loads the points configurations from viper, and exits if invalid.
+func MustLoadPointsConfig() service.PointsConfig {
+	var config service.PointsConfig
+	viper.MustUnmarshalKey("points", &config)
+
+	if err := config.Precision.Validate(); err != nil {
+		logrus.WithError(err).Fatal("Invalid points precision config")
+	}
+
+	return config
+}
@@ -0,0 +1,94 @@
+package util
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/Conflux-Chain/go-conflux-util/viper"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// StoreConfig extends the store configurations of go-conflux-util with PostgreSQL, which takes precedence over
+// MySQL and SQLite if specified.
+type StoreConfig struct {
+	store.Config `mapstructure:",squash"`
+
+	Postgres *PostgresConfig
+}
+
+type PostgresConfig struct {
+	DSN string // e.g. host=localhost user=root password=xxx dbname=points_service port=5432 sslmode=disable
+}
+
+// MustOpenStoreFromViper opens the database configured by viper key "store".
+//
+// Note, tables are not auto migrated, please use migration package to apply schema changes.
+func MustOpenStoreFromViper() *store.Store {
+	var config StoreConfig
+	viper.MustUnmarshalKey("store", &config)
+
+	db, err := config.OpenOrCreate()
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to open or create database")
+	}
+
+	return store.NewStore(db)
+}
+
+func (config *StoreConfig) OpenOrCreate(tables ...any) (*gorm.DB, error) {
+	if config.Postgres == nil {
+		return config.Config.OpenOrCreate(tables...)
+	}
+
+	db, err := gorm.Open(postgres.Open(config.Postgres.DSN), &gorm.Config{
+		Logger: logger.New(
+			log.New(os.Stdout, "\r\n", log.LstdFlags),
+			logger.Config{
+				SlowThreshold:             config.SlowThreshold,
+				LogLevel:                  config.gormLogLevel(),
+				IgnoreRecordNotFoundError: true,
+				Colorful:                  true,
+			},
+		),
+	})
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to open postgres database")
+	}
+
+	sqlDb, err := db.DB()
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failed to retrieve sql.DB")
+	}
+
+	if err := db.AutoMigrate(tables...); err != nil {
+		sqlDb.Close()
+		return nil, errors.WithMessage(err, "Failed to auto migrate tables")
+	}
+
+	sqlDb.SetConnMaxLifetime(config.ConnMaxLifetime)
+	sqlDb.SetMaxOpenConns(config.MaxOpenConns)
+	sqlDb.SetMaxIdleConns(config.MaxIdleConns)
+
+	logrus.Debug("PostgreSQL database initialized")
+
+	return db, nil
+}
+
+func (config *StoreConfig) gormLogLevel() logger.LogLevel {
+	switch strings.ToLower(config.LogLevel) {
+	case "silent":
+		return logger.Silent
+	case "info":
+		return logger.Info
+	case "error":
+		return logger.Error
+	default:
+		return logger.Warn
+	}
+}
@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/pools": {
-            "get": {
-                "description": "List pools in pagination view.",
+        "/actions/referral": {
+            "post": {
+                "description": "Bind the referrer for the request signer, which is allowed only once. Request should be signed in EIP-712\ntyped data Request(string method,string path,bytes body,uint256 nonce,uint256 deadline), and the signer,\nnonce, deadline and signature are specified in headers X-Signer, X-Nonce, X-Deadline and X-Signature.\nNote, path is the public URL path without query as requested, e.g. /points/api/actions/referral.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,54 +25,127 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Pool"
+                    "Referral"
                 ],
-                "summary": "List pools",
+                "summary": "Bind referrer by signed request",
                 "parameters": [
                     {
-                        "minimum": 0,
+                        "type": "string",
+                        "description": "Signer address",
+                        "name": "X-Signer",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
                         "type": "integer",
-                        "default": 0,
-                        "description": "The number of skipped records, usually it's pageSize * (pageNumber - 1)",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Unused nonce of signer",
+                        "name": "X-Nonce",
+                        "in": "header",
+                        "required": true
                     },
                     {
-                        "maximum": 100,
-                        "minimum": 1,
                         "type": "integer",
-                        "description": "The number of records displayed on the page",
-                        "name": "limit",
-                        "in": "query",
+                        "description": "Unix timestamp in seconds that request expires",
+                        "name": "X-Deadline",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "enum": [
-                            "asc",
-                            "desc"
-                        ],
                         "type": "string",
-                        "default": "desc",
-                        "description": "Sort in ASC or DESC order by sortField",
-                        "name": "sort",
-                        "in": "query"
+                        "description": "EIP-712 signature in hex",
+                        "name": "X-Signature",
+                        "in": "header",
+                        "required": true
                     },
                     {
-                        "enum": [
-                            "tvl",
-                            "trade",
-                            "liquidity"
-                        ],
+                        "description": "Referral binding request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.BindReferrerActionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Bound referral",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Referral"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/pools/weight": {
+            "post": {
+                "description": "Upsert the trade and liquidity weights of pool, where zero value will not be updated for existing pool.\nAuthenticated by X-Api-Key header, or EIP-712 signed request of allowlisted signer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Upsert pool weight",
+                "parameters": [
+                    {
                         "type": "string",
-                        "default": "tvl",
-                        "description": "The field used for sorting. The value is tvl, trade or liquidity",
-                        "name": "sortField",
-                        "in": "query"
+                        "description": "Admin API key",
+                        "name": "X-Api-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Pool weight request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AdminPoolWeightRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Paged pools",
+                        "description": "Pool weight updated",
+                        "schema": {
+                            "$ref": "#/definitions/api.BusinessError"
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
                         "schema": {
                             "allOf": [
                                 {
@@ -82,12 +155,52 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.PagingResult-model_PoolInfo"
+                                            "type": "string"
                                         }
                                     }
                                 }
                             ]
                         }
+                    }
+                }
+            }
+        },
+        "/admin/tokens/override": {
+            "post": {
+                "description": "Override the logo URL and display symbol of token, where empty value clears the override.\nAuthenticated by X-Api-Key header, or EIP-712 signed request of allowlisted signer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Override token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin API key",
+                        "name": "X-Api-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Token override request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AdminTokenOverrideRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token overridden",
+                        "schema": {
+                            "$ref": "#/definitions/api.BusinessError"
+                        }
                     },
                     "600": {
                         "description": "Internal server error",
@@ -110,9 +223,137 @@ const docTemplate = `{
                 }
             }
         },
-        "/users": {
+        "/admin/users": {
+            "post": {
+                "description": "Insert a new user with trade and liquidity points.\nAuthenticated by X-Api-Key header, or EIP-712 signed request of allowlisted signer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Insert user points",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin API key",
+                        "name": "X-Api-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User points request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AdminUserPointsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Id of inserted user",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "integer"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/points": {
+            "post": {
+                "description": "Increase or decrease the trade and liquidity points of user by delta values, where negative value\nmeans decrease. Points cannot be negative after update.\nAuthenticated by X-Api-Key header, or EIP-712 signed request of allowlisted signer.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Update user points",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Admin API key",
+                        "name": "X-Api-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User points delta request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.AdminUserPointsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User points updated",
+                        "schema": {
+                            "$ref": "#/definitions/api.BusinessError"
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/pools": {
             "get": {
-                "description": "List users in pagination view.",
+                "description": "List pools in pagination view.",
                 "consumes": [
                     "application/json"
                 ],
@@ -120,9 +361,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Pool"
                 ],
-                "summary": "List users",
+                "summary": "List pools",
                 "parameters": [
                     {
                         "minimum": 0,
@@ -154,19 +395,20 @@ const docTemplate = `{
                     },
                     {
                         "enum": [
+                            "tvl",
                             "trade",
                             "liquidity"
                         ],
                         "type": "string",
-                        "default": "trade",
-                        "description": "The field used for sorting. The value is trade or liquidity",
+                        "default": "tvl",
+                        "description": "The field used for sorting. The value is tvl, trade or liquidity",
                         "name": "sortField",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Paged users",
+                        "description": "Paged pools",
                         "schema": {
                             "allOf": [
                                 {
@@ -176,7 +418,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.PagingResultWithUpdatedAt-model_UserInfo"
+                                            "$ref": "#/definitions/model.PagingResult-model_PoolInfo"
                                         }
                                     }
                                 }
@@ -203,21 +445,836 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.BusinessError": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "description": "Code error code, 0 indicates success, otherwise business error (e.g. user not found) or internal server error.",
-                    "type": "integer"
-                },
-                "data": {
-                    "description": "Data is the return value if success. Otherwise, it is concret failure reason in string type."
-                },
-                "message": {
-                    "description": "Message error message associated with ` + "`" + `Code` + "`" + `.",
+        },
+        "/pools/{address}": {
+            "get": {
+                "description": "Get pool detail, including token metadata, fee tier, accumulated points and current weights.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pool"
+                ],
+                "summary": "Get pool",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pool address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pool detail",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.PoolDetail"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/pools/{address}/metrics": {
+            "get": {
+                "description": "Get the latest metrics of pool snapshots in time range, including USD volume, liquidity value-seconds,\nunique traders and liquidity providers, TVL and token prices. Records are in ascending order of timestamp.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pool"
+                ],
+                "summary": "Get pool metrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pool address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Start unix timestamp in seconds (inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "End unix timestamp in seconds (inclusive), 0 means now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "The maximum number of records",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pool metrics",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.PoolSnapshot"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/pools/{address}/tvl-history": {
+            "get": {
+                "description": "Get the latest TVL snapshots of pool in time range, which are in ascending order of timestamp.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pool"
+                ],
+                "summary": "Get pool TVL history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pool address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Start unix timestamp in seconds (inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "End unix timestamp in seconds (inclusive), 0 means now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "The maximum number of records",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pool TVL history",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.PoolTvlInfo"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/referrals": {
+            "post": {
+                "description": "Bind the referrer for a user, which is allowed only once. The user should sign the EIP-191 message\n\"Bind referrer {referrer} for {address} before {deadline}\" with both addresses in lowercase hex, and\ndeadline is the unix timestamp in seconds that signature expires.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Referral"
+                ],
+                "summary": "Bind referrer",
+                "parameters": [
+                    {
+                        "description": "Referral binding request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.BindReferrerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Bound referral",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Referral"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/referrals/{address}": {
+            "get": {
+                "description": "Get the referrer and number of referees of specified user.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Referral"
+                ],
+                "summary": "Get referral",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Referral info",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.ReferralInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens": {
+            "get": {
+                "description": "List all tokens of tracked pools with the latest USD price and total TVL, which are in descending order of TVL.\nLogo URL and display symbol are manually overridden, and empty if not overridden.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Token"
+                ],
+                "summary": "List tokens",
+                "responses": {
+                    "200": {
+                        "description": "Tokens",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.TokenInfo"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/tokens/{address}/prices": {
+            "get": {
+                "description": "Get the latest USD prices of token in time range, which were used to evaluate trade and liquidity values\nof snapshots. Records are in ascending order of timestamp.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Token"
+                ],
+                "summary": "Get token prices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Start unix timestamp in seconds (inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "End unix timestamp in seconds (inclusive), 0 means now",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "The maximum number of records",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token prices",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.TokenPriceInfo"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "List users in pagination view.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "default": 0,
+                        "description": "The number of skipped records, usually it's pageSize * (pageNumber - 1)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "The number of records displayed on the page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "desc",
+                        "description": "Sort in ASC or DESC order by sortField",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "trade",
+                            "liquidity",
+                            "referral"
+                        ],
+                        "type": "string",
+                        "default": "trade",
+                        "description": "The field used for sorting. The value is trade, liquidity or referral",
+                        "name": "sortField",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Paged users",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.PagingResultWithUpdatedAt-model_UserInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{address}/proof": {
+            "get": {
+                "description": "Get the airdrop amount and Merkle proof of specified user, which could be verified by OpenZeppelin's MerkleProof.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get Merkle proof",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Season id, the latest season by default",
+                        "name": "season",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merkle proof",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.MerkleProofInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "600": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.BusinessError"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "string"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.BusinessError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code error code, 0 indicates success, otherwise business error (e.g. user not found) or internal server error.",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "Data is the return value if success. Otherwise, it is concret failure reason in string type."
+                },
+                "message": {
+                    "description": "Message error message associated with ` + "`" + `Code` + "`" + `.",
+                    "type": "string"
+                }
+            }
+        },
+        "model.AdminPoolWeightRequest": {
+            "type": "object",
+            "required": [
+                "pool",
+                "reason"
+            ],
+            "properties": {
+                "liquidityWeight": {
+                    "description": "liquidity weight, at most 3 decimals",
+                    "type": "number"
+                },
+                "pool": {
+                    "description": "pool address",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "reason for audit",
+                    "type": "string"
+                },
+                "tradeWeight": {
+                    "description": "trade weight, at most 3 decimals",
+                    "type": "number"
+                }
+            }
+        },
+        "model.AdminTokenOverrideRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "token"
+            ],
+            "properties": {
+                "displaySymbol": {
+                    "description": "display symbol, empty to clear",
+                    "type": "string"
+                },
+                "logoUrl": {
+                    "description": "logo URL, empty to clear",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "reason for audit",
+                    "type": "string"
+                },
+                "token": {
+                    "description": "token address",
+                    "type": "string"
+                }
+            }
+        },
+        "model.AdminUserPointsRequest": {
+            "type": "object",
+            "required": [
+                "reason",
+                "user"
+            ],
+            "properties": {
+                "liquidityPoints": {
+                    "description": "liquidity points, at most configured decimals",
+                    "type": "number"
+                },
+                "reason": {
+                    "description": "reason for audit",
+                    "type": "string"
+                },
+                "tradePoints": {
+                    "description": "trade points, at most configured decimals",
+                    "type": "number"
+                },
+                "user": {
+                    "description": "user address",
+                    "type": "string"
+                }
+            }
+        },
+        "model.BindReferrerActionRequest": {
+            "type": "object",
+            "required": [
+                "referrer"
+            ],
+            "properties": {
+                "referrer": {
+                    "description": "referrer address",
+                    "type": "string"
+                }
+            }
+        },
+        "model.BindReferrerRequest": {
+            "type": "object",
+            "required": [
+                "address",
+                "deadline",
+                "referrer",
+                "signature"
+            ],
+            "properties": {
+                "address": {
+                    "description": "referee address",
+                    "type": "string"
+                },
+                "deadline": {
+                    "description": "unix timestamp in seconds that signature expires",
+                    "type": "integer"
+                },
+                "referrer": {
+                    "description": "referrer address",
+                    "type": "string"
+                },
+                "signature": {
+                    "description": "EIP-191 signature of referee",
+                    "type": "string"
+                }
+            }
+        },
+        "model.MerkleProofInfo": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "amount": {
+                    "description": "amount in the smallest token unit",
+                    "type": "number"
+                },
+                "proof": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "root": {
+                    "type": "string"
+                },
+                "season": {
                     "type": "string"
                 }
             }
@@ -253,6 +1310,56 @@ const docTemplate = `{
                 }
             }
         },
+        "model.PoolDetail": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "fee": {
+                    "type": "integer"
+                },
+                "liquidityPoints": {
+                    "type": "number"
+                },
+                "liquidityWeight": {
+                    "type": "number"
+                },
+                "token0": {
+                    "type": "string"
+                },
+                "token0Decimals": {
+                    "type": "integer"
+                },
+                "token0Name": {
+                    "type": "string"
+                },
+                "token0Symbol": {
+                    "type": "string"
+                },
+                "token1": {
+                    "type": "string"
+                },
+                "token1Decimals": {
+                    "type": "integer"
+                },
+                "token1Name": {
+                    "type": "string"
+                },
+                "token1Symbol": {
+                    "type": "string"
+                },
+                "tradePoints": {
+                    "type": "number"
+                },
+                "tradeWeight": {
+                    "type": "number"
+                },
+                "tvl": {
+                    "type": "number"
+                }
+            }
+        },
         "model.PoolInfo": {
             "type": "object",
             "properties": {
@@ -285,6 +1392,157 @@ const docTemplate = `{
                 }
             }
         },
+        "model.PoolSnapshot": {
+            "type": "object",
+            "properties": {
+                "liquidityProviders": {
+                    "description": "number of unique liquidity providers",
+                    "type": "integer"
+                },
+                "liquidityValueSeconds": {
+                    "type": "number"
+                },
+                "pool": {
+                    "type": "string"
+                },
+                "price0": {
+                    "description": "token0 price in USD",
+                    "type": "number"
+                },
+                "price1": {
+                    "description": "token1 price in USD",
+                    "type": "number"
+                },
+                "timestamp": {
+                    "description": "unix timestamp in seconds",
+                    "type": "integer"
+                },
+                "traders": {
+                    "description": "number of unique traders",
+                    "type": "integer"
+                },
+                "tvl": {
+                    "description": "TVL at the end of snapshot",
+                    "type": "number"
+                },
+                "volumeUsd": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.PoolTvlInfo": {
+            "type": "object",
+            "properties": {
+                "timestamp": {
+                    "type": "integer"
+                },
+                "tvl": {
+                    "type": "number"
+                }
+            }
+        },
+        "model.Referral": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "referee address",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "referrer": {
+                    "description": "referrer address",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ReferralInfo": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "description": "user address",
+                    "type": "string"
+                },
+                "referees": {
+                    "description": "number of referees",
+                    "type": "integer"
+                },
+                "referrer": {
+                    "description": "referrer address, empty if not bound yet",
+                    "type": "string"
+                }
+            }
+        },
+        "model.TokenInfo": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "decimals": {
+                    "type": "integer"
+                },
+                "displaySymbol": {
+                    "description": "overridden symbol, empty if not overridden",
+                    "type": "string"
+                },
+                "logoUrl": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "description": "latest price in USD, 0 if not priced yet",
+                    "type": "number"
+                },
+                "priceTime": {
+                    "description": "unix timestamp in seconds of latest price",
+                    "type": "integer"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "tvl": {
+                    "description": "total TVL in USD of all tracked pools",
+                    "type": "number"
+                }
+            }
+        },
+        "model.TokenPriceInfo": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "description": "price in USD",
+                    "type": "number"
+                },
+                "route": {
+                    "description": "token route to calculate price, e.g. [token, WCFX, USDT]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "samples": {
+                    "description": "number of sampled blocks",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "e.g. swappi or vswap",
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "integer"
+                }
+            }
+        },
         "model.UserInfo": {
             "type": "object",
             "properties": {
@@ -294,6 +1552,9 @@ const docTemplate = `{
                 "liquidityPoints": {
                     "type": "number"
                 },
+                "referralPoints": {
+                    "type": "number"
+                },
                 "tradePoints": {
                     "type": "number"
                 }
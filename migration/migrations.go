@@ -0,0 +1,243 @@
+package migration
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Migrations is the ordered list of all schema migrations. Any schema change must be appended as a new migration
+// with a greater version, and applied migrations must never be modified.
+//
+// Note, migrations must refer to frozen versioned structs of schema_v*.go rather than the latest models, so that
+// a migration always applies the same schema regardless of later model changes. Besides, migrations should be
+// idempotent, e.g. check whether column or index exists before adding it, since a failed migration may be half
+// applied on MySQL (see Migrator.Up).
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "baseline schema",
+		Up: func(tx *gorm.DB) error {
+			// compatible with existing databases that were created by AutoMigrate
+			return tx.Migrator().AutoMigrate(baselineTables...)
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(baselineTables...)
+		},
+		// drops all core tables, e.g. users and pools
+		Destructive: true,
+	},
+	{
+		Version:     2,
+		Description: "widen points precision and add residuals of user points",
+		Up: func(tx *gorm.DB) error {
+			return alterColumns(tx, []columnChange{
+				{&userV2{}, "TradePoints", false},
+				{&userV2{}, "LiquidityPoints", false},
+				{&userV2{}, "TradeResidual", true},
+				{&userV2{}, "LiquidityResidual", true},
+				{&poolV2{}, "TradePoints", false},
+				{&poolV2{}, "LiquidityPoints", false},
+			})
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range []string{"TradeResidual", "LiquidityResidual"} {
+				if err := tx.Migrator().DropColumn(&userV2{}, field); err != nil {
+					return err
+				}
+			}
+
+			return alterColumns(tx, []columnChange{
+				{&userV1{}, "TradePoints", false},
+				{&userV1{}, "LiquidityPoints", false},
+				{&poolV1{}, "TradePoints", false},
+				{&poolV1{}, "LiquidityPoints", false},
+			})
+		},
+	},
+	{
+		Version:     3,
+		Description: "add pool snapshots",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AutoMigrate(&poolSnapshotV3{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&poolSnapshotV3{})
+		},
+	},
+	{
+		Version:     4,
+		Description: "add metrics of pool snapshots",
+		Up: func(tx *gorm.DB) error {
+			var changes []columnChange
+			for _, field := range poolSnapshotMetrics {
+				changes = append(changes, columnChange{&poolSnapshotV4{}, field, true})
+			}
+
+			return alterColumns(tx, changes)
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range poolSnapshotMetrics {
+				if err := tx.Migrator().DropColumn(&poolSnapshotV4{}, field); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		Version:     5,
+		Description: "add token prices",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AutoMigrate(&tokenPriceV5{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&tokenPriceV5{})
+		},
+	},
+	{
+		Version:     6,
+		Description: "add tokens registry and token TVL of pools",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Migrator().AutoMigrate(&tokenV6{}); err != nil {
+				return err
+			}
+
+			if err := alterColumns(tx, []columnChange{
+				{&poolV6{}, "Tvl0", true},
+				{&poolV6{}, "Tvl1", true},
+			}); err != nil {
+				return err
+			}
+
+			return backfillTokens(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, field := range []string{"Tvl0", "Tvl1"} {
+				if err := tx.Migrator().DropColumn(&poolV6{}, field); err != nil {
+					return err
+				}
+			}
+
+			return tx.Migrator().DropTable(&tokenV6{})
+		},
+	},
+	{
+		Version:     7,
+		Description: "add metadata cache",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AutoMigrate(&metadataCacheV7{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&metadataCacheV7{})
+		},
+	},
+	{
+		Version:     8,
+		Description: "add sync checkpoints",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AutoMigrate(&checkpointV8{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&checkpointV8{})
+		},
+	},
+	{
+		Version:     9,
+		Description: "add applied snapshots",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AutoMigrate(&appliedSnapshotV9{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&appliedSnapshotV9{})
+		},
+	},
+	{
+		Version:     10,
+		Description: "widen referral points precision and add residual of referral points",
+		Up: func(tx *gorm.DB) error {
+			return alterColumns(tx, []columnChange{
+				{&userV10{}, "ReferralPoints", false},
+				{&userV10{}, "ReferralResidual", true},
+			})
+		},
+		Down: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&userV10{}, "ReferralResidual"); err != nil {
+				return err
+			}
+
+			return alterColumns(tx, []columnChange{
+				{&userV2{}, "ReferralPoints", false},
+			})
+		},
+	},
+	{
+		Version:     11,
+		Description: "add skipped snapshots of pools",
+		Up: func(tx *gorm.DB) error {
+			return tx.Migrator().AutoMigrate(&skippedSnapshotV11{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&skippedSnapshotV11{})
+		},
+	},
+}
+
+// backfillTokens registers tokens of existing pools, whose TVL will be updated once pools traded again.
+func backfillTokens(tx *gorm.DB) error {
+	var pools []*poolV6
+	if err := tx.Order("id ASC").Find(&pools).Error; err != nil {
+		return err
+	}
+
+	var tokens []*tokenV6
+	registered := make(map[string]bool)
+	for _, v := range pools {
+		for _, token := range []*tokenV6{
+			{Address: v.Token0, Name: v.Token0Name, Symbol: v.Token0Symbol, Decimals: v.Token0Decimals},
+			{Address: v.Token1, Name: v.Token1Name, Symbol: v.Token1Symbol, Decimals: v.Token1Decimals},
+		} {
+			if !registered[token.Address] {
+				registered[token.Address] = true
+				token.Model.CreatedAt, token.Model.UpdatedAt = v.Model.CreatedAt, v.Model.UpdatedAt
+				tokens = append(tokens, token)
+			}
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(tokens, 500).Error
+}
+
+// columnChange alters the column of model field, or adds the column if absent.
+type columnChange struct {
+	model  any
+	field  string
+	create bool // add column if absent
+}
+
+func alterColumns(tx *gorm.DB, changes []columnChange) error {
+	migrator := tx.Migrator()
+
+	for _, v := range changes {
+		if !v.create {
+			if err := migrator.AlterColumn(v.model, v.field); err != nil {
+				return err
+			}
+		} else if !migrator.HasColumn(v.model, v.field) {
+			if err := migrator.AddColumn(v.model, v.field); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewDefaultMigrator creates a migrator with all schema migrations.
+func NewDefaultMigrator(db *gorm.DB) *Migrator {
+	return NewMigrator(db, Migrations)
+}
@@ -0,0 +1,236 @@
+package migration
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Migration is a versioned schema change with up and down steps.
+type Migration struct {
+	Version     uint32
+	Description string
+	Up          func(tx *gorm.DB) error
+	Down        func(tx *gorm.DB) error
+
+	// Destructive indicates that roll back loses data that could not be recovered by applying again, which
+	// requires to force the roll back explicitly.
+	Destructive bool
+}
+
+// SchemaMigration records an applied migration in database.
+type SchemaMigration struct {
+	Version     uint32    `gorm:"primarykey;autoIncrement:false"`
+	Description string    `gorm:"size:256;not null"`
+	AppliedAt   time.Time `gorm:"not null"`
+}
+
+// MigrationStatus is the status of a migration.
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies or rolls back migrations in order of version.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator with given migrations, which will be sorted by version.
+func NewMigrator(db *gorm.DB, migrations []Migration) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	return &Migrator{db, sorted}
+}
+
+// Latest returns the latest version of all migrations.
+func (m *Migrator) Latest() uint32 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Current returns the latest applied version, or 0 if no migration applied.
+func (m *Migrator) Current() (uint32, error) {
+	if err := m.ensureTable(); err != nil {
+		return 0, err
+	}
+
+	var applied SchemaMigration
+	if err := m.db.Order("version DESC").Limit(1).Find(&applied).Error; err != nil {
+		return 0, errors.WithMessage(err, "Failed to get the latest applied migration")
+	}
+
+	return applied.Version, nil
+}
+
+// Status returns the status of all migrations in order of version.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]MigrationStatus, 0, len(m.migrations))
+	for _, v := range m.migrations {
+		status := MigrationStatus{Migration: v}
+		if record, ok := applied[v.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = record.AppliedAt
+		}
+
+		result = append(result, status)
+	}
+
+	return result, nil
+}
+
+// Up applies all pending migrations up to the target version, or the latest version if target is 0.
+//
+// Note, each migration is applied in a transaction, but MySQL commits DDL statements implicitly, so a failed
+// migration may be half applied without being recorded on MySQL. In this case, fix the cause and apply again,
+// which requires migrations to be idempotent.
+func (m *Migrator) Up(target uint32) (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	if target == 0 {
+		target = m.Latest()
+	}
+
+	var count int
+	for _, v := range m.migrations {
+		if v.Version > target {
+			break
+		}
+
+		if _, ok := applied[v.Version]; ok {
+			continue
+		}
+
+		if err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := v.Up(tx); err != nil {
+				return err
+			}
+
+			return tx.Create(&SchemaMigration{
+				Version:     v.Version,
+				Description: v.Description,
+				AppliedAt:   time.Now(),
+			}).Error
+		}); err != nil {
+			return count, errors.WithMessagef(err, "Failed to apply migration %v", v.Version)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"version":     v.Version,
+			"description": v.Description,
+		}).Info("Migration applied")
+
+		count++
+	}
+
+	return count, nil
+}
+
+// Down rolls back the latest n applied migrations, and refuses to roll back any destructive migration unless forced.
+//
+// Note, the same as Up, a failed roll back may be half applied on MySQL.
+func (m *Migrator) Down(n int, force bool) (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for i := len(m.migrations) - 1; i >= 0 && count < n; i-- {
+		v := m.migrations[i]
+		if _, ok := applied[v.Version]; !ok {
+			continue
+		}
+
+		if v.Down == nil {
+			return count, errors.Errorf("Migration %v is irreversible", v.Version)
+		}
+
+		if v.Destructive && !force {
+			return count, errors.Errorf("Migration %v is destructive, which requires to force roll back", v.Version)
+		}
+
+		if err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := v.Down(tx); err != nil {
+				return err
+			}
+
+			return tx.Delete(&SchemaMigration{}, "version = ?", v.Version).Error
+		}); err != nil {
+			return count, errors.WithMessagef(err, "Failed to roll back migration %v", v.Version)
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"version":     v.Version,
+			"description": v.Description,
+		}).Info("Migration rolled back")
+
+		count++
+	}
+
+	return count, nil
+}
+
+// MustBeLatest exits if any migration is not applied yet.
+func (m *Migrator) MustBeLatest() {
+	status, err := m.Status()
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to get migration status")
+	}
+
+	for _, v := range status {
+		if !v.Applied {
+			logrus.WithFields(logrus.Fields{
+				"version":     v.Version,
+				"description": v.Description,
+				"latest":      m.Latest(),
+			}).Fatal("Database schema is behind, please run `points-service migrate up` at first")
+		}
+	}
+}
+
+func (m *Migrator) ensureTable() error {
+	if err := m.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return errors.WithMessage(err, "Failed to create schema migrations table")
+	}
+
+	return nil
+}
+
+func (m *Migrator) applied() (map[uint32]SchemaMigration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var records []SchemaMigration
+	if err := m.db.Find(&records).Error; err != nil {
+		return nil, errors.WithMessage(err, "Failed to get applied migrations")
+	}
+
+	result := make(map[uint32]SchemaMigration, len(records))
+	for _, v := range records {
+		result[v.Version] = v
+	}
+
+	return result, nil
+}
@@ -0,0 +1,160 @@
+package migration
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/v3-Swampy/points-service/model"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	return db
+}
+
+// TestMigrateUpToLatestModels checks that migrations from the frozen baseline result in the latest models.
+func TestMigrateUpToLatestModels(t *testing.T) {
+	db := newTestDB(t)
+	migrator := NewDefaultMigrator(db)
+
+	if _, err := migrator.Up(0); err != nil {
+		t.Fatalf("Failed to migrate up: %v", err)
+	}
+
+	models := []any{
+		&model.User{}, &model.Pool{}, &model.PoolParams{}, &model.Config{}, &model.Referral{},
+		&model.SignedRequest{}, &model.MerkleDistribution{}, &model.MerkleProof{}, &model.AuditLog{},
+		&model.PoolSnapshot{}, &model.TokenPrice{}, &model.Token{}, &model.MetadataCache{},
+		&model.Checkpoint{}, &model.AppliedSnapshot{}, &model.SkippedSnapshot{},
+	}
+
+	for _, v := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(v); err != nil {
+			t.Fatalf("Failed to parse model %T: %v", v, err)
+		}
+
+		if !db.Migrator().HasTable(v) {
+			t.Fatalf("Table %v not created", stmt.Schema.Table)
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if len(field.DBName) == 0 || field.IgnoreMigration {
+				continue
+			}
+
+			if !db.Migrator().HasColumn(v, field.DBName) {
+				t.Fatalf("Column %v.%v not created", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+}
+
+func TestMigrateDownBaseline(t *testing.T) {
+	db := newTestDB(t)
+	migrator := NewDefaultMigrator(db)
+
+	if _, err := migrator.Up(0); err != nil {
+		t.Fatalf("Failed to migrate up: %v", err)
+	}
+
+	latest := int(migrator.Latest())
+
+	// roll back all but the baseline, and then refuse to drop baseline tables
+	count, err := migrator.Down(latest, false)
+	if err == nil {
+		t.Fatal("Expected error to roll back baseline without force")
+	}
+
+	if count != latest-1 {
+		t.Fatalf("Expected %v migrations rolled back, got %v", latest-1, count)
+	}
+
+	if current, _ := migrator.Current(); current != 1 {
+		t.Fatalf("Expected current version 1, got %v", current)
+	}
+
+	if !db.Migrator().HasTable(&userV1{}) {
+		t.Fatal("Baseline tables dropped without force")
+	}
+
+	if _, err = migrator.Down(1, true); err != nil {
+		t.Fatalf("Failed to force roll back baseline: %v", err)
+	}
+
+	if db.Migrator().HasTable(&userV1{}) {
+		t.Fatal("Baseline tables not dropped")
+	}
+
+	// apply again after rolled back
+	if _, err = migrator.Up(0); err != nil {
+		t.Fatalf("Failed to migrate up again: %v", err)
+	}
+}
+
+func TestMigratePoolSnapshotMetrics(t *testing.T) {
+	db := newTestDB(t)
+	migrator := NewDefaultMigrator(db)
+
+	// version 3 creates pool snapshots without metrics
+	if _, err := migrator.Up(3); err != nil {
+		t.Fatalf("Failed to migrate up to version 3: %v", err)
+	}
+
+	if !db.Migrator().HasTable(&poolSnapshotV3{}) {
+		t.Fatal("Pool snapshots not created")
+	}
+
+	for _, field := range poolSnapshotMetrics {
+		if db.Migrator().HasColumn(&poolSnapshotV4{}, field) {
+			t.Fatalf("Metrics column %v created before version 4", field)
+		}
+	}
+
+	// version 4 adds metrics
+	if _, err := migrator.Up(4); err != nil {
+		t.Fatalf("Failed to migrate up to version 4: %v", err)
+	}
+
+	for _, field := range poolSnapshotMetrics {
+		if !db.Migrator().HasColumn(&poolSnapshotV4{}, field) {
+			t.Fatalf("Metrics column %v not added", field)
+		}
+	}
+
+	// roll back version 4 and then version 3
+	if _, err := migrator.Down(1, false); err != nil {
+		t.Fatalf("Failed to roll back version 4: %v", err)
+	}
+
+	for _, field := range poolSnapshotMetrics {
+		if db.Migrator().HasColumn(&poolSnapshotV4{}, field) {
+			t.Fatalf("Metrics column %v not dropped", field)
+		}
+	}
+
+	if _, err := migrator.Down(1, false); err != nil {
+		t.Fatalf("Failed to roll back version 3: %v", err)
+	}
+
+	if db.Migrator().HasTable(&poolSnapshotV3{}) {
+		t.Fatal("Pool snapshots not dropped")
+	}
+}
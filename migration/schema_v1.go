@@ -0,0 +1,134 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Schema of version 1, which is frozen as the baseline for databases created by AutoMigrate before versioned
+// migrations introduced. Never change these structs, and append a new migration for any schema change instead.
+
+type modelV1 struct {
+	ID        uint64    `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+type userV1 struct {
+	Model           modelV1         `gorm:"embedded"`
+	Address         string          `gorm:"size:64;not null;unique"`
+	TradePoints     decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0;index"`
+	LiquidityPoints decimal.Decimal `gorm:"type:decimal(21,1);not null;default:0;index"`
+	ReferralPoints  decimal.Decimal `gorm:"type:decimal(21,1);not null;default:0;index"`
+}
+
+func (userV1) TableName() string { return "users" }
+
+type poolV1 struct {
+	Model           modelV1         `gorm:"embedded"`
+	Address         string          `gorm:"size:64;not null;unique"`
+	Token0          string          `gorm:"size:64;not null"`
+	Token1          string          `gorm:"size:64;not null"`
+	Fee             uint32          `gorm:"not null"`
+	Tvl             decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0;index"`
+	TradePoints     decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0"`
+	LiquidityPoints decimal.Decimal `gorm:"type:decimal(21,1);not null;default:0"`
+
+	Token0Name     string `gorm:"size:128"`
+	Token0Symbol   string `gorm:"size:128"`
+	Token0Decimals uint8  `gorm:""`
+	Token1Name     string `gorm:"size:128"`
+	Token1Symbol   string `gorm:"size:128"`
+	Token1Decimals uint8  `gorm:""`
+}
+
+func (poolV1) TableName() string { return "pools" }
+
+type configV1 struct {
+	ID        uint32
+	Name      string `gorm:"unique;size:128;not null"`
+	Value     string `gorm:"size:1024;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (configV1) TableName() string { return "configs" }
+
+type poolParamsV1 struct {
+	Model           modelV1         `gorm:"embedded"`
+	Address         string          `gorm:"size:64;not null;unique"`
+	TradeWeight     decimal.Decimal `gorm:"type:decimal(6,3);not null;index"`
+	LiquidityWeight decimal.Decimal `gorm:"type:decimal(6,3);not null;index"`
+}
+
+func (poolParamsV1) TableName() string { return "pool_params" }
+
+type referralV1 struct {
+	Model    modelV1 `gorm:"embedded"`
+	Address  string  `gorm:"size:64;not null;unique"`
+	Referrer string  `gorm:"size:64;not null;index"`
+}
+
+func (referralV1) TableName() string { return "referrals" }
+
+type signedRequestV1 struct {
+	ID        uint64
+	Signer    string `gorm:"size:64;not null;uniqueIndex:idx_signer_nonce,priority:1"`
+	Nonce     uint64 `gorm:"not null;uniqueIndex:idx_signer_nonce,priority:2"`
+	Deadline  int64  `gorm:"not null"`
+	Method    string `gorm:"size:16;not null"`
+	Path      string `gorm:"size:256;not null"`
+	CreatedAt time.Time
+}
+
+func (signedRequestV1) TableName() string { return "signed_requests" }
+
+type merkleDistributionV1 struct {
+	Model    modelV1         `gorm:"embedded"`
+	Season   string          `gorm:"size:64;not null;unique"`
+	Root     string          `gorm:"size:66;not null"`
+	Rate     decimal.Decimal `gorm:"type:decimal(36,18);not null"`
+	Decimals uint8           `gorm:"not null"`
+	Total    decimal.Decimal `gorm:"type:decimal(65,0);not null"`
+	Accounts int             `gorm:"not null"`
+}
+
+func (merkleDistributionV1) TableName() string { return "merkle_distributions" }
+
+type merkleProofV1 struct {
+	ID      uint64
+	Season  string          `gorm:"size:64;not null;uniqueIndex:idx_season_address,priority:1"`
+	Address string          `gorm:"size:64;not null;uniqueIndex:idx_season_address,priority:2"`
+	Amount  decimal.Decimal `gorm:"type:decimal(65,0);not null"`
+	Proof   string          `gorm:"type:text;not null"`
+}
+
+func (merkleProofV1) TableName() string { return "merkle_proofs" }
+
+type auditLogV1 struct {
+	ID        uint64    `gorm:"primarykey"`
+	Operator  string    `gorm:"size:128;not null;index"`
+	Action    string    `gorm:"size:64;not null;index"`
+	Target    string    `gorm:"size:64;not null;index"`
+	Reason    string    `gorm:"size:1024;not null"`
+	Params    string    `gorm:"type:text"`
+	Before    string    `gorm:"type:text"`
+	After     string    `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"not null;index"`
+}
+
+func (auditLogV1) TableName() string { return "audit_logs" }
+
+// baselineTables are tables created by AutoMigrate before versioned migrations introduced.
+var baselineTables = []any{
+	&userV1{},
+	&poolV1{},
+	&poolParamsV1{},
+	&configV1{},
+	&referralV1{},
+	&signedRequestV1{},
+	&merkleDistributionV1{},
+	&merkleProofV1{},
+	&auditLogV1{},
+}
@@ -0,0 +1,20 @@
+package migration
+
+import "github.com/shopspring/decimal"
+
+// Schema of version 10, which widens referral points precision and adds residual of referral points. Never change
+// these structs.
+
+type userV10 struct {
+	Model           modelV1         `gorm:"embedded"`
+	Address         string          `gorm:"size:64;not null;unique"`
+	TradePoints     decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0;index"`
+	LiquidityPoints decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0;index"`
+	ReferralPoints  decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0;index"`
+
+	TradeResidual     decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0"`
+	LiquidityResidual decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0"`
+	ReferralResidual  decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0"`
+}
+
+func (userV10) TableName() string { return "users" }
@@ -0,0 +1,20 @@
+package migration
+
+import "time"
+
+// Schema of version 11, which adds skipped snapshots of pools. Never change these structs.
+
+type skippedSnapshotV11 struct {
+	ID             uint64
+	Pool           string     `gorm:"size:64;not null;uniqueIndex:idx_skipped_pool_timestamp,priority:1"`
+	Timestamp      int64      `gorm:"not null;uniqueIndex:idx_skipped_pool_timestamp,priority:2"`
+	MinBlockNumber uint64     `gorm:"not null"`
+	MaxBlockNumber uint64     `gorm:"not null"`
+	Trades         int        `gorm:"not null"`
+	Liquidities    int        `gorm:"not null"`
+	Reason         string     `gorm:"size:1024;not null"`
+	ReplayedAt     *time.Time `gorm:"index"`
+	CreatedAt      time.Time
+}
+
+func (skippedSnapshotV11) TableName() string { return "skipped_snapshots" }
@@ -0,0 +1,38 @@
+package migration
+
+import "github.com/shopspring/decimal"
+
+// Schema of version 2, which widens points precision and adds residuals of user points. Never change these structs.
+
+type userV2 struct {
+	Model           modelV1         `gorm:"embedded"`
+	Address         string          `gorm:"size:64;not null;unique"`
+	TradePoints     decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0;index"`
+	LiquidityPoints decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0;index"`
+	ReferralPoints  decimal.Decimal `gorm:"type:decimal(21,1);not null;default:0;index"`
+
+	TradeResidual     decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0"`
+	LiquidityResidual decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0"`
+}
+
+func (userV2) TableName() string { return "users" }
+
+type poolV2 struct {
+	Model           modelV1         `gorm:"embedded"`
+	Address         string          `gorm:"size:64;not null;unique"`
+	Token0          string          `gorm:"size:64;not null"`
+	Token1          string          `gorm:"size:64;not null"`
+	Fee             uint32          `gorm:"not null"`
+	Tvl             decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0;index"`
+	TradePoints     decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0"`
+	LiquidityPoints decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0"`
+
+	Token0Name     string `gorm:"size:128"`
+	Token0Symbol   string `gorm:"size:128"`
+	Token0Decimals uint8  `gorm:""`
+	Token1Name     string `gorm:"size:128"`
+	Token1Symbol   string `gorm:"size:128"`
+	Token1Decimals uint8  `gorm:""`
+}
+
+func (poolV2) TableName() string { return "pools" }
@@ -0,0 +1,15 @@
+package migration
+
+import "github.com/shopspring/decimal"
+
+// Schema of version 3, which adds pool snapshots without metrics, since metrics are added in version 4. Never
+// change these structs.
+
+type poolSnapshotV3 struct {
+	ID        uint64
+	Pool      string          `gorm:"size:64;not null;uniqueIndex:idx_pool_timestamp,priority:1"`
+	Timestamp int64           `gorm:"not null;uniqueIndex:idx_pool_timestamp,priority:2"`
+	Tvl       decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0"`
+}
+
+func (poolSnapshotV3) TableName() string { return "pool_snapshots" }
@@ -0,0 +1,24 @@
+package migration
+
+import "github.com/shopspring/decimal"
+
+// Schema of version 4, which adds metrics of pool snapshots. Never change these structs.
+
+type poolSnapshotV4 struct {
+	ID        uint64
+	Pool      string          `gorm:"size:64;not null;uniqueIndex:idx_pool_timestamp,priority:1"`
+	Timestamp int64           `gorm:"not null;uniqueIndex:idx_pool_timestamp,priority:2"`
+	Tvl       decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0"`
+
+	VolumeUsd             decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0"`
+	LiquidityValueSeconds decimal.Decimal `gorm:"type:decimal(56,18);not null;default:0"`
+	Traders               int             `gorm:"not null;default:0"`
+	LiquidityProviders    int             `gorm:"not null;default:0"`
+	Price0                decimal.Decimal `gorm:"type:decimal(36,18);not null;default:0"`
+	Price1                decimal.Decimal `gorm:"type:decimal(36,18);not null;default:0"`
+}
+
+func (poolSnapshotV4) TableName() string { return "pool_snapshots" }
+
+// poolSnapshotMetrics are the metrics fields added to pool snapshots in version 4.
+var poolSnapshotMetrics = []string{"VolumeUsd", "LiquidityValueSeconds", "Traders", "LiquidityProviders", "Price0", "Price1"}
@@ -0,0 +1,17 @@
+package migration
+
+import "github.com/shopspring/decimal"
+
+// Schema of version 5, which adds token prices. Never change these structs.
+
+type tokenPriceV5 struct {
+	ID        uint64
+	Token     string          `gorm:"size:64;not null;uniqueIndex:idx_token_timestamp,priority:1"`
+	Timestamp int64           `gorm:"not null;uniqueIndex:idx_token_timestamp,priority:2"`
+	Price     decimal.Decimal `gorm:"type:decimal(36,18);not null"`
+	Source    string          `gorm:"size:16;not null"`
+	Route     string          `gorm:"size:256;not null"`
+	Samples   int             `gorm:"not null"`
+}
+
+func (tokenPriceV5) TableName() string { return "token_prices" }
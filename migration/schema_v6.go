@@ -0,0 +1,39 @@
+package migration
+
+import "github.com/shopspring/decimal"
+
+// Schema of version 6, which adds tokens registry and token TVL of pools. Never change these structs.
+
+type tokenV6 struct {
+	Model         modelV1 `gorm:"embedded"`
+	Address       string  `gorm:"size:64;not null;unique"`
+	Name          string  `gorm:"size:128"`
+	Symbol        string  `gorm:"size:128"`
+	Decimals      uint8   `gorm:""`
+	LogoURL       string  `gorm:"size:512"`
+	DisplaySymbol string  `gorm:"size:128"`
+}
+
+func (tokenV6) TableName() string { return "tokens" }
+
+type poolV6 struct {
+	Model           modelV1         `gorm:"embedded"`
+	Address         string          `gorm:"size:64;not null;unique"`
+	Token0          string          `gorm:"size:64;not null"`
+	Token1          string          `gorm:"size:64;not null"`
+	Fee             uint32          `gorm:"not null"`
+	Tvl             decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0;index"`
+	Tvl0            decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0"`
+	Tvl1            decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0"`
+	TradePoints     decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0"`
+	LiquidityPoints decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0"`
+
+	Token0Name     string `gorm:"size:128"`
+	Token0Symbol   string `gorm:"size:128"`
+	Token0Decimals uint8  `gorm:""`
+	Token1Name     string `gorm:"size:128"`
+	Token1Symbol   string `gorm:"size:128"`
+	Token1Decimals uint8  `gorm:""`
+}
+
+func (poolV6) TableName() string { return "pools" }
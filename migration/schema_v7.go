@@ -0,0 +1,16 @@
+package migration
+
+import "time"
+
+// Schema of version 7, which adds metadata cache. Never change these structs.
+
+type metadataCacheV7 struct {
+	ID        uint64
+	Namespace string `gorm:"size:32;not null;uniqueIndex:idx_namespace_key,priority:1"`
+	Key       string `gorm:"column:cache_key;size:128;not null;uniqueIndex:idx_namespace_key,priority:2"`
+	Value     string `gorm:"type:text;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (metadataCacheV7) TableName() string { return "metadata_caches" }
@@ -0,0 +1,17 @@
+package migration
+
+import "time"
+
+// Schema of version 8, which adds sync checkpoints. Never change these structs.
+
+type checkpointV8 struct {
+	ID             uint32
+	Stage          string `gorm:"unique;size:32;not null"`
+	Timestamp      int64  `gorm:"not null"`
+	MinBlockNumber uint64 `gorm:"not null"`
+	MaxBlockNumber uint64 `gorm:"not null"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (checkpointV8) TableName() string { return "checkpoints" }
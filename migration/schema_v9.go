@@ -0,0 +1,15 @@
+package migration
+
+import "time"
+
+// Schema of version 9, which adds applied snapshots. Never change these structs.
+
+type appliedSnapshotV9 struct {
+	ID             uint64
+	Timestamp      int64  `gorm:"unique;not null"`
+	MinBlockNumber uint64 `gorm:"not null"`
+	MaxBlockNumber uint64 `gorm:"not null"`
+	CreatedAt      time.Time
+}
+
+func (appliedSnapshotV9) TableName() string { return "applied_snapshots" }
@@ -18,7 +18,7 @@ func (p *PagingRequest) IsDesc() bool {
 
 type UserPagingRequest struct {
 	PagingRequest
-	SortField string `form:"sortField,default=trade" binding:"oneof=trade liquidity"`
+	SortField string `form:"sortField,default=trade" binding:"oneof=trade liquidity referral"`
 }
 
 type PoolPagingRequest struct {
@@ -41,6 +41,24 @@ type UserInfo struct {
 	Address         string          `json:"address"`
 	TradePoints     decimal.Decimal `json:"tradePoints"`
 	LiquidityPoints decimal.Decimal `json:"liquidityPoints"`
+	ReferralPoints  decimal.Decimal `json:"referralPoints"`
+}
+
+type BindReferrerRequest struct {
+	Address   string `json:"address" binding:"required"`   // referee address
+	Referrer  string `json:"referrer" binding:"required"`  // referrer address
+	Deadline  int64  `json:"deadline" binding:"required"`  // unix timestamp in seconds that signature expires
+	Signature string `json:"signature" binding:"required"` // EIP-191 signature of referee
+}
+
+type BindReferrerActionRequest struct {
+	Referrer string `json:"referrer" binding:"required"` // referrer address
+}
+
+type ReferralInfo struct {
+	Address  string `json:"address"`  // user address
+	Referrer string `json:"referrer"` // referrer address, empty if not bound yet
+	Referees int64  `json:"referees"` // number of referees
 }
 
 type PoolParamInfo struct {
@@ -58,3 +76,77 @@ type PoolInfo struct {
 	Fee uint32          `json:"fee"`
 	Tvl decimal.Decimal `json:"tvl"`
 }
+
+type PoolDetail struct {
+	PoolInfo
+	Token0Name      string          `json:"token0Name"`
+	Token0Decimals  uint8           `json:"token0Decimals"`
+	Token1Name      string          `json:"token1Name"`
+	Token1Decimals  uint8           `json:"token1Decimals"`
+	TradePoints     decimal.Decimal `json:"tradePoints"`
+	LiquidityPoints decimal.Decimal `json:"liquidityPoints"`
+}
+
+type HistoryRequest struct {
+	From  int64 `form:"from" binding:"min=0"`                                 // inclusive unix timestamp in seconds
+	To    int64 `form:"to" binding:"min=0"`                                   // inclusive unix timestamp in seconds, 0 means now
+	Limit int   `form:"limit,default=100" binding:"omitempty,min=1,max=1000"` // maximum number of records
+}
+
+type PoolTvlInfo struct {
+	Timestamp int64           `json:"timestamp"`
+	Tvl       decimal.Decimal `json:"tvl"`
+}
+
+type TokenPriceInfo struct {
+	Timestamp int64           `json:"timestamp"`
+	Price     decimal.Decimal `json:"price"`   // price in USD
+	Source    string          `json:"source"`  // e.g. swappi or vswap
+	Route     []string        `json:"route"`   // token route to calculate price, e.g. [token, WCFX, USDT]
+	Samples   int             `json:"samples"` // number of sampled blocks
+}
+
+type TokenInfo struct {
+	Address       string          `json:"address"`
+	Name          string          `json:"name"`
+	Symbol        string          `json:"symbol"`
+	Decimals      uint8           `json:"decimals"`
+	LogoURL       string          `json:"logoUrl"`
+	DisplaySymbol string          `json:"displaySymbol"` // overridden symbol, empty if not overridden
+	Price         decimal.Decimal `json:"price"`         // latest price in USD, 0 if not priced yet
+	PriceTime     int64           `json:"priceTime"`     // unix timestamp in seconds of latest price
+	Tvl           decimal.Decimal `json:"tvl"`           // total TVL in USD of all tracked pools
+}
+
+type MerkleProofRequest struct {
+	Season string `form:"season"` // latest season by default
+}
+
+type MerkleProofInfo struct {
+	Season  string          `json:"season"`
+	Root    string          `json:"root"`
+	Address string          `json:"address"`
+	Amount  decimal.Decimal `json:"amount"` // amount in the smallest token unit
+	Proof   []string        `json:"proof"`
+}
+
+type AdminPoolWeightRequest struct {
+	Pool            string          `json:"pool" binding:"required"`   // pool address
+	TradeWeight     decimal.Decimal `json:"tradeWeight"`               // trade weight, at most 3 decimals
+	LiquidityWeight decimal.Decimal `json:"liquidityWeight"`           // liquidity weight, at most 3 decimals
+	Reason          string          `json:"reason" binding:"required"` // reason for audit
+}
+
+type AdminUserPointsRequest struct {
+	User            string          `json:"user" binding:"required"`   // user address
+	TradePoints     decimal.Decimal `json:"tradePoints"`               // trade points, at most configured decimals
+	LiquidityPoints decimal.Decimal `json:"liquidityPoints"`           // liquidity points, at most configured decimals
+	Reason          string          `json:"reason" binding:"required"` // reason for audit
+}
+
+type AdminTokenOverrideRequest struct {
+	Token         string `json:"token" binding:"required"`  // token address
+	LogoURL       string `json:"logoUrl"`                   // logo URL, empty to clear
+	DisplaySymbol string `json:"displaySymbol"`             // display symbol, empty to clear
+	Reason        string `json:"reason" binding:"required"` // reason for audit
+}
@@ -7,8 +7,6 @@ import (
 	"github.com/v3-Swampy/points-service/blockchain"
 )
 
-var Tables = []any{&User{}, &Pool{}, &PoolParams{}, &Config{}}
-
 type Model struct {
 	ID        uint64    `gorm:"primarykey" json:"id"`
 	CreatedAt time.Time `gorm:"not null" json:"createdAt"`
@@ -18,8 +16,14 @@ type Model struct {
 type User struct {
 	Model
 	Address         string          `gorm:"size:64;not null;unique" json:"address"`
-	TradePoints     decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0;index" json:"tradePoints"`
-	LiquidityPoints decimal.Decimal `gorm:"type:decimal(21,1);not null;default:0;index" json:"liquidityPoints"`
+	TradePoints     decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0;index" json:"tradePoints"`
+	LiquidityPoints decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0;index" json:"liquidityPoints"`
+	ReferralPoints  decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0;index" json:"referralPoints"`
+
+	// sub-unit remainders below the configured precision, which are carried across batches
+	TradeResidual     decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0" json:"-"`
+	LiquidityResidual decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0" json:"-"`
+	ReferralResidual  decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0" json:"-"`
 }
 
 func NewUser(address string, tradePoints decimal.Decimal, liquidityPoints decimal.Decimal, time time.Time) *User {
@@ -41,8 +45,10 @@ type Pool struct {
 	Token1          string          `gorm:"size:64;not null" json:"token1"`
 	Fee             uint32          `gorm:"not null" json:"fee"`
 	Tvl             decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0;index" json:"tvl"`
-	TradePoints     decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0" json:"tradePoints"`
-	LiquidityPoints decimal.Decimal `gorm:"type:decimal(21,1);not null;default:0" json:"liquidityPoints"`
+	Tvl0            decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0" json:"tvl0"` // TVL of token0
+	Tvl1            decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0" json:"tvl1"` // TVL of token1
+	TradePoints     decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0" json:"tradePoints"`
+	LiquidityPoints decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0" json:"liquidityPoints"`
 
 	Token0Name     string `gorm:"size:128" json:"token0Name"`
 	Token0Symbol   string `gorm:"size:128" json:"token0Symbol"`
@@ -59,6 +65,8 @@ func NewPool(pool blockchain.PoolInfo, tradePoints decimal.Decimal, liquidityPoi
 		Token1:          pool.Token1.Address.String(),
 		Fee:             pool.Fee,
 		Tvl:             decimal.Zero,
+		Tvl0:            decimal.Zero,
+		Tvl1:            decimal.Zero,
 		TradePoints:     tradePoints,
 		LiquidityPoints: liquidityPoints,
 
@@ -90,3 +98,152 @@ type PoolParams struct {
 	TradeWeight     decimal.Decimal `gorm:"type:decimal(6,3);not null;index" json:"tradeWeight"`
 	LiquidityWeight decimal.Decimal `gorm:"type:decimal(6,3);not null;index" json:"liquidityWeight"`
 }
+
+// Referral binds a user (referee) to the referrer, which could be bound only once.
+type Referral struct {
+	Model
+	Address  string `gorm:"size:64;not null;unique" json:"address"` // referee address
+	Referrer string `gorm:"size:64;not null;index" json:"referrer"` // referrer address
+}
+
+// SignedRequest records the consumed nonce of signed request to prevent replay attack.
+type SignedRequest struct {
+	ID        uint64
+	Signer    string `gorm:"size:64;not null;uniqueIndex:idx_signer_nonce,priority:1"`
+	Nonce     uint64 `gorm:"not null;uniqueIndex:idx_signer_nonce,priority:2"`
+	Deadline  int64  `gorm:"not null"` // unix timestamp in seconds
+	Method    string `gorm:"size:16;not null"`
+	Path      string `gorm:"size:256;not null"`
+	CreatedAt time.Time
+}
+
+// MerkleDistribution is the Merkle root of token airdrop converted from user points in a season.
+type MerkleDistribution struct {
+	Model
+	Season   string          `gorm:"size:64;not null;unique" json:"season"`
+	Root     string          `gorm:"size:66;not null" json:"root"`
+	Rate     decimal.Decimal `gorm:"type:decimal(36,18);not null" json:"rate"` // tokens per point
+	Decimals uint8           `gorm:"not null" json:"decimals"`                 // token decimals
+	Total    decimal.Decimal `gorm:"type:decimal(65,0);not null" json:"total"` // total amount in the smallest token unit
+	Accounts int             `gorm:"not null" json:"accounts"`
+}
+
+// MerkleProof is the Merkle proof of airdrop amount for an account in a season.
+type MerkleProof struct {
+	ID      uint64
+	Season  string          `gorm:"size:64;not null;uniqueIndex:idx_season_address,priority:1"`
+	Address string          `gorm:"size:64;not null;uniqueIndex:idx_season_address,priority:2"`
+	Amount  decimal.Decimal `gorm:"type:decimal(65,0);not null"` // amount in the smallest token unit
+	Proof   string          `gorm:"type:text;not null"`          // JSON array of hex encoded hashes
+}
+
+// AuditLog records the manual change of user points, pool weights or token overrides.
+type AuditLog struct {
+	ID        uint64    `gorm:"primarykey" json:"id"`
+	Operator  string    `gorm:"size:128;not null;index" json:"operator"` // operator name or signer address
+	Action    string    `gorm:"size:64;not null;index" json:"action"`
+	Target    string    `gorm:"size:64;not null;index" json:"target"` // user, pool or token address
+	Reason    string    `gorm:"size:1024;not null" json:"reason"`
+	Params    string    `gorm:"type:text" json:"params"` // JSON encoded request parameters
+	Before    string    `gorm:"type:text" json:"before"` // JSON encoded values before change, empty if not exists
+	After     string    `gorm:"type:text" json:"after"`  // JSON encoded values after change
+	CreatedAt time.Time `gorm:"not null;index" json:"createdAt"`
+}
+
+// PoolSnapshot is the pool metrics of a snapshot, in which pool has any trade or liquidity event.
+type PoolSnapshot struct {
+	ID        uint64          `json:"-"`
+	Pool      string          `gorm:"size:64;not null;uniqueIndex:idx_pool_timestamp,priority:1" json:"pool"`
+	Timestamp int64           `gorm:"not null;uniqueIndex:idx_pool_timestamp,priority:2" json:"timestamp"` // unix timestamp in seconds
+	Tvl       decimal.Decimal `gorm:"type:decimal(20,0);not null;default:0" json:"tvl"`                    // TVL at the end of snapshot
+
+	VolumeUsd             decimal.Decimal `gorm:"type:decimal(38,18);not null;default:0" json:"volumeUsd"`
+	LiquidityValueSeconds decimal.Decimal `gorm:"type:decimal(56,18);not null;default:0" json:"liquidityValueSeconds"`
+	Traders               int             `gorm:"not null;default:0" json:"traders"`                    // number of unique traders
+	LiquidityProviders    int             `gorm:"not null;default:0" json:"liquidityProviders"`         // number of unique liquidity providers
+	Price0                decimal.Decimal `gorm:"type:decimal(36,18);not null;default:0" json:"price0"` // token0 price in USD
+	Price1                decimal.Decimal `gorm:"type:decimal(36,18);not null;default:0" json:"price1"` // token1 price in USD
+}
+
+// TokenPrice is the token price in USD used to evaluate trade and liquidity values of a snapshot.
+type TokenPrice struct {
+	ID        uint64
+	Token     string          `gorm:"size:64;not null;uniqueIndex:idx_token_timestamp,priority:1"`
+	Timestamp int64           `gorm:"not null;uniqueIndex:idx_token_timestamp,priority:2"` // unix timestamp in seconds
+	Price     decimal.Decimal `gorm:"type:decimal(36,18);not null"`                        // average price of all samples
+	Source    string          `gorm:"size:16;not null"`                                    // e.g. swappi or vswap
+	Route     string          `gorm:"size:256;not null"`                                   // JSON array of token addresses
+	Samples   int             `gorm:"not null"`                                            // number of sampled blocks
+}
+
+// Token is the ERC20 token of tracked pools, where logo URL and display symbol are manually overridden.
+type Token struct {
+	Model
+	Address       string `gorm:"size:64;not null;unique" json:"address"`
+	Name          string `gorm:"size:128" json:"name"`
+	Symbol        string `gorm:"size:128" json:"symbol"`
+	Decimals      uint8  `gorm:"" json:"decimals"`
+	LogoURL       string `gorm:"size:512" json:"logoUrl"`
+	DisplaySymbol string `gorm:"size:128" json:"displaySymbol"`
+}
+
+func NewToken(token blockchain.TokenInfo, time time.Time) *Token {
+	return &Token{
+		Address:  token.Address.String(),
+		Name:     token.Name,
+		Symbol:   token.Symbol,
+		Decimals: token.Decimals,
+		Model: Model{
+			CreatedAt: time,
+			UpdatedAt: time,
+		},
+	}
+}
+
+// MetadataCache is the persistent cache of on-chain metadata, e.g. token info and pool tokens, which could be
+// invalidated explicitly.
+type MetadataCache struct {
+	ID        uint64
+	Namespace string `gorm:"size:32;not null;uniqueIndex:idx_namespace_key,priority:1"`                   // e.g. token, pair or pool
+	Key       string `gorm:"column:cache_key;size:128;not null;uniqueIndex:idx_namespace_key,priority:2"` // key is reserved in MySQL
+	Value     string `gorm:"type:text;not null"`                                                          // JSON encoded value
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Checkpoint is the progress of a sync stage, which records the block range of the last processed snapshot, so
+// that sync resumes with the next contiguous block window after restart.
+type Checkpoint struct {
+	ID             uint32
+	Stage          string `gorm:"unique;size:32;not null"` // e.g. stat
+	Timestamp      int64  `gorm:"not null"`                // timestamp of the last processed snapshot
+	MinBlockNumber uint64 `gorm:"not null"`
+	MaxBlockNumber uint64 `gorm:"not null"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// AppliedSnapshot is the contract parser snapshot that applied to points, so that any snapshot will be applied
+// exactly once, e.g. in case of duplicate delivery.
+type AppliedSnapshot struct {
+	ID             uint64
+	Timestamp      int64  `gorm:"unique;not null"`
+	MinBlockNumber uint64 `gorm:"not null"`
+	MaxBlockNumber uint64 `gorm:"not null"`
+	CreatedAt      time.Time
+}
+
+// SkippedSnapshot records events of pool that skipped in an applied snapshot, e.g. pool quarantined, which could be
+// replayed by backfill later.
+type SkippedSnapshot struct {
+	ID             uint64
+	Pool           string     `gorm:"size:64;not null;uniqueIndex:idx_skipped_pool_timestamp,priority:1"`
+	Timestamp      int64      `gorm:"not null;uniqueIndex:idx_skipped_pool_timestamp,priority:2"`
+	MinBlockNumber uint64     `gorm:"not null"`
+	MaxBlockNumber uint64     `gorm:"not null"`
+	Trades         int        `gorm:"not null"`           // number of skipped trade records
+	Liquidities    int        `gorm:"not null"`           // number of skipped liquidity records
+	Reason         string     `gorm:"size:1024;not null"` // error that caused to skip
+	ReplayedAt     *time.Time `gorm:"index"`              // nil if not replayed yet
+	CreatedAt      time.Time
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/shopspring/decimal"
+	"github.com/v3-Swampy/points-service/model"
+	"gorm.io/gorm"
+)
+
+// Audit actions of manual changes.
+const (
+	AuditActionPoolWeightUpsert = "poolweight.upsert"
+	AuditActionUserPointsInsert = "userpoints.insert"
+	AuditActionUserPointsUpdate = "userpoints.update"
+	AuditActionTokenOverride    = "token.override"
+)
+
+// AuditOperator identifies who made a manual change and why.
+type AuditOperator struct {
+	Operator string // operator name or signer address
+	Reason   string
+}
+
+// AuditLogFilter filters audit logs, where empty or zero value means no filter.
+type AuditLogFilter struct {
+	Operator string
+	Action   string
+	Target   string
+	From     time.Time // inclusive
+	To       time.Time // exclusive
+	Offset   int
+	Limit    int
+}
+
+type poolWeightValues struct {
+	TradeWeight     decimal.Decimal `json:"tradeWeight"`
+	LiquidityWeight decimal.Decimal `json:"liquidityWeight"`
+}
+
+type userPointsValues struct {
+	TradePoints     decimal.Decimal `json:"tradePoints"`
+	LiquidityPoints decimal.Decimal `json:"liquidityPoints"`
+}
+
+type tokenOverrideValues struct {
+	LogoURL       string `json:"logoUrl"`
+	DisplaySymbol string `json:"displaySymbol"`
+}
+
+// AuditService applies manual changes of user points, pool weights and token overrides, and records the before
+// and after values in audit log within the same database transaction.
+type AuditService struct {
+	store     *store.Store
+	user      *UserService
+	poolParam *PoolParamService
+	token     *TokenService
+}
+
+func NewAuditService(store *store.Store, user *UserService, poolParam *PoolParamService, token *TokenService) *AuditService {
+	return &AuditService{
+		store:     store,
+		user:      user,
+		poolParam: poolParam,
+		token:     token,
+	}
+}
+
+// UpsertPoolWeight upserts the pool weights and records audit log.
+func (service *AuditService) UpsertPoolWeight(op AuditOperator, pool string, tradeWeight, liquidityWeight decimal.Decimal) error {
+	params := poolWeightValues{tradeWeight, liquidityWeight}
+
+	return service.store.DB.Transaction(func(dbTx *gorm.DB) error {
+		before, err := service.getPoolWeight(pool, dbTx)
+		if err != nil {
+			return err
+		}
+
+		if err = service.poolParam.Upsert(pool, tradeWeight, liquidityWeight, dbTx); err != nil {
+			return err
+		}
+
+		after, err := service.getPoolWeight(pool, dbTx)
+		if err != nil {
+			return err
+		}
+
+		return service.add(op, AuditActionPoolWeightUpsert, pool, params, before, after, dbTx)
+	})
+}
+
+// AddUser inserts a new user with points and records audit log.
+func (service *AuditService) AddUser(op AuditOperator, address string, tradePoints, liquidityPoints decimal.Decimal) (id uint64, err error) {
+	params := userPointsValues{tradePoints, liquidityPoints}
+
+	err = service.store.DB.Transaction(func(dbTx *gorm.DB) error {
+		if id, err = service.user.Add(address, tradePoints, liquidityPoints, dbTx); err != nil {
+			return err
+		}
+
+		after, err := service.getUserPoints(address, dbTx)
+		if err != nil {
+			return err
+		}
+
+		return service.add(op, AuditActionUserPointsInsert, address, params, nil, after, dbTx)
+	})
+
+	return
+}
+
+// DeltaUpdateUser increases or decreases user points by delta values and records audit log.
+func (service *AuditService) DeltaUpdateUser(op AuditOperator, address string, tradePoints, liquidityPoints decimal.Decimal) error {
+	params := userPointsValues{tradePoints, liquidityPoints}
+
+	return service.store.DB.Transaction(func(dbTx *gorm.DB) error {
+		before, err := service.getUserPoints(address, dbTx)
+		if err != nil {
+			return err
+		}
+
+		if err = service.user.DeltaUpdate(address, tradePoints, liquidityPoints, dbTx); err != nil {
+			return err
+		}
+
+		after, err := service.getUserPoints(address, dbTx)
+		if err != nil {
+			return err
+		}
+
+		return service.add(op, AuditActionUserPointsUpdate, address, params, before, after, dbTx)
+	})
+}
+
+// SetTokenOverrides overrides the logo URL and display symbol of token and records audit log.
+func (service *AuditService) SetTokenOverrides(op AuditOperator, token, logoURL, displaySymbol string) error {
+	params := tokenOverrideValues{logoURL, displaySymbol}
+
+	return service.store.DB.Transaction(func(dbTx *gorm.DB) error {
+		before, err := service.getTokenOverrides(token, dbTx)
+		if err != nil {
+			return err
+		}
+
+		if before == nil {
+			return api.ErrValidationStr("Failed to find token by address")
+		}
+
+		if err = service.token.SetOverrides(token, logoURL, displaySymbol, dbTx); err != nil {
+			return err
+		}
+
+		after, err := service.getTokenOverrides(token, dbTx)
+		if err != nil {
+			return err
+		}
+
+		return service.add(op, AuditActionTokenOverride, token, params, before, after, dbTx)
+	})
+}
+
+// List returns the audit logs in reverse chronological order.
+func (service *AuditService) List(filter AuditLogFilter) (logs []*model.AuditLog, err error) {
+	db := service.store.DB.Model(&model.AuditLog{})
+
+	if len(filter.Operator) > 0 {
+		db = db.Where("operator = ?", filter.Operator)
+	}
+
+	if len(filter.Action) > 0 {
+		db = db.Where("action = ?", filter.Action)
+	}
+
+	if len(filter.Target) > 0 {
+		db = db.Where("LOWER(target) = ?", strings.ToLower(filter.Target))
+	}
+
+	if !filter.From.IsZero() {
+		db = db.Where("created_at >= ?", filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		db = db.Where("created_at < ?", filter.To)
+	}
+
+	if filter.Limit > 0 {
+		db = db.Offset(filter.Offset).Limit(filter.Limit)
+	}
+
+	if err = db.Order("id DESC").Find(&logs).Error; err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to list audit logs")
+	}
+
+	return logs, nil
+}
+
+// getPoolWeight returns the current pool weights, or nil if pool not configured.
+func (service *AuditService) getPoolWeight(pool string, dbTx *gorm.DB) (*poolWeightValues, error) {
+	var param model.PoolParams
+	found, err := store.NewStore(dbTx).Get(&param, "address = ?", pool)
+	if err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get pool param values by address")
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return &poolWeightValues{param.TradeWeight, param.LiquidityWeight}, nil
+}
+
+// getUserPoints returns the current user points, or nil if user not found.
+func (service *AuditService) getUserPoints(address string, dbTx *gorm.DB) (*userPointsValues, error) {
+	var user model.User
+	found, err := store.NewStore(dbTx).Get(&user, "address = ?", address)
+	if err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get user by address")
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return &userPointsValues{user.TradePoints, user.LiquidityPoints}, nil
+}
+
+// getTokenOverrides returns the current token overrides, or nil if token not found.
+func (service *AuditService) getTokenOverrides(address string, dbTx *gorm.DB) (*tokenOverrideValues, error) {
+	token, err := service.token.Get(address, dbTx)
+	if err != nil || token == nil {
+		return nil, err
+	}
+
+	return &tokenOverrideValues{token.LogoURL, token.DisplaySymbol}, nil
+}
+
+// add records an audit log for the manual change on target, where params, before and after values will be
+// encoded in JSON. Note, nil before value means target not exists before change.
+func (service *AuditService) add(op AuditOperator, action, target string, params, before, after any, dbTx *gorm.DB) error {
+	log := model.AuditLog{
+		Operator: op.Operator,
+		Action:   action,
+		Target:   target,
+		Reason:   op.Reason,
+	}
+
+	encodedParams, err := json.Marshal(params)
+	if err != nil {
+		return api.ErrInternal(err)
+	}
+	log.Params = string(encodedParams)
+
+	if !isNilValue(before) {
+		encodedBefore, err := json.Marshal(before)
+		if err != nil {
+			return api.ErrInternal(err)
+		}
+		log.Before = string(encodedBefore)
+	}
+
+	encodedAfter, err := json.Marshal(after)
+	if err != nil {
+		return api.ErrInternal(err)
+	}
+	log.After = string(encodedAfter)
+
+	if err := dbTx.Create(&log).Error; err != nil {
+		return api.ErrDatabaseCause(err, "Failed to create audit log")
+	}
+
+	return nil
+}
+
+func isNilValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case *poolWeightValues:
+		return v == nil
+	case *userPointsValues:
+		return v == nil
+	case *tokenOverrideValues:
+		return v == nil
+	default:
+		return false
+	}
+}
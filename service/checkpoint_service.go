@@ -0,0 +1,151 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/pkg/errors"
+	"github.com/v3-Swampy/points-service/model"
+	"github.com/v3-Swampy/points-service/sync"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrSnapshotApplied is returned when applying any snapshot that already applied.
+var ErrSnapshotApplied = errors.New("Snapshot already applied")
+
+// Sync stages that persist checkpoints.
+const (
+	CheckpointStageStat = "stat" // snapshots applied to points
+)
+
+type CheckpointService struct {
+	store *store.Store
+}
+
+func NewCheckpointService(store *store.Store) *CheckpointService {
+	return &CheckpointService{
+		store: store,
+	}
+}
+
+// Get returns the time info of the last processed snapshot of stage, or false if not found.
+func (service *CheckpointService) Get(stage string, dbTx ...*gorm.DB) (sync.TimeInfo, bool, error) {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	var checkpoint model.Checkpoint
+	found, err := store.NewStore(db).Get(&checkpoint, "stage = ?", stage)
+	if err != nil {
+		return sync.TimeInfo{}, false, api.ErrDatabaseCause(err, "Failed to get checkpoint")
+	}
+
+	if !found {
+		return sync.TimeInfo{}, false, nil
+	}
+
+	return sync.TimeInfo{
+		Timestamp:      checkpoint.Timestamp,
+		MinBlockNumber: checkpoint.MinBlockNumber,
+		MaxBlockNumber: checkpoint.MaxBlockNumber,
+	}, true, nil
+}
+
+// Upsert updates the time info of the last processed snapshot of stage.
+func (service *CheckpointService) Upsert(stage string, info sync.TimeInfo, dbTx ...*gorm.DB) error {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	now := time.Now()
+
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "stage"}},
+		DoUpdates: clause.AssignmentColumns([]string{"timestamp", "min_block_number", "max_block_number", "updated_at"}),
+	}).Create(&model.Checkpoint{
+		Stage:          stage,
+		Timestamp:      info.Timestamp,
+		MinBlockNumber: info.MinBlockNumber,
+		MaxBlockNumber: info.MaxBlockNumber,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}).Error; err != nil {
+		return api.ErrDatabaseCause(err, "Failed to upsert checkpoint")
+	}
+
+	return nil
+}
+
+// Applied returns the timestamps of given snapshots that already applied.
+func (service *CheckpointService) Applied(timestamps []int64, dbTx ...*gorm.DB) (map[int64]bool, error) {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	result := make(map[int64]bool)
+	if len(timestamps) == 0 {
+		return result, nil
+	}
+
+	var applied []int64
+	if err := db.Model(&model.AppliedSnapshot{}).Where("timestamp IN ?", timestamps).
+		Pluck("timestamp", &applied).Error; err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get applied snapshots")
+	}
+
+	for _, v := range applied {
+		result[v] = true
+	}
+
+	return result, nil
+}
+
+// MarkApplied marks the given snapshots as applied, and returns ErrSnapshotApplied if any already applied. It
+// should be called in the same transaction that applies snapshots.
+func (service *CheckpointService) MarkApplied(snapshots []sync.TimeInfo, dbTx ...*gorm.DB) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	timestamps := make([]int64, 0, len(snapshots))
+	for _, v := range snapshots {
+		timestamps = append(timestamps, v.Timestamp)
+	}
+
+	applied, err := service.Applied(timestamps, db)
+	if err != nil {
+		return err
+	}
+
+	if len(applied) > 0 {
+		return errors.WithMessagef(ErrSnapshotApplied, "%v of %v snapshots", len(applied), len(snapshots))
+	}
+
+	now := time.Now()
+	records := make([]*model.AppliedSnapshot, 0, len(snapshots))
+	for _, v := range snapshots {
+		records = append(records, &model.AppliedSnapshot{
+			Timestamp:      v.Timestamp,
+			MinBlockNumber: v.MinBlockNumber,
+			MaxBlockNumber: v.MaxBlockNumber,
+			CreatedAt:      now,
+		})
+	}
+
+	// unique timestamp rejects snapshots applied concurrently
+	if err := db.Create(&records).Error; err != nil {
+		return api.ErrDatabaseCause(err, "Failed to mark snapshots applied")
+	}
+
+	return nil
+}
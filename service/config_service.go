@@ -85,3 +85,13 @@ func (cs *ConfigService) GetLastStatPointsTime() (int64, error) {
 func (cs *ConfigService) UpsertLastStatPointsTime(timestamp int64, dbTx ...*gorm.DB) error {
 	return cs.StoreConfig(CfgKeyLastStatTimePoints, strconv.FormatInt(timestamp, 10), dbTx...)
 }
+
+// Ping verifies that the database is reachable.
+func (cs *ConfigService) Ping() error {
+	db, err := cs.store.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	return db.Ping()
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/pkg/errors"
+	"github.com/v3-Swampy/points-service/model"
+	"gorm.io/gorm/clause"
+)
+
+// ErrLeaseHeld is returned when acquiring a lease that held by another owner and not expired yet.
+var ErrLeaseHeld = errors.New("Lease held by another owner")
+
+// Leases that persisted in configs.
+const (
+	LeaseSync = "lease.sync" // apply snapshots to points, e.g. by the sync of service or backfill command
+)
+
+// lease is the JSON encoded config value of lease.
+type lease struct {
+	Owner     string `json:"owner"`
+	ExpiresAt int64  `json:"expiresAt"` // unix timestamp in milliseconds
+}
+
+// LeaseService grants lease to owner exclusively across processes, e.g. so that the sync of service and backfill
+// command will not apply snapshots concurrently. Lease should be renewed before expired, and other owners could take
+// over once expired, e.g. in case of process crashed.
+type LeaseService struct {
+	store *store.Store
+}
+
+func NewLeaseService(store *store.Store) *LeaseService {
+	return &LeaseService{
+		store: store,
+	}
+}
+
+// Acquire acquires or renews the lease for owner with given TTL, and returns ErrLeaseHeld if held by another owner.
+func (service *LeaseService) Acquire(name, owner string, ttl time.Duration) error {
+	now := time.Now()
+
+	value, err := json.Marshal(lease{owner, now.Add(ttl).UnixMilli()})
+	if err != nil {
+		return errors.WithMessage(err, "Failed to encode lease")
+	}
+
+	var config model.Config
+	found, err := service.store.Get(&config, "name = ?", name)
+	if err != nil {
+		return api.ErrDatabaseCause(err, "Failed to get lease")
+	}
+
+	if !found {
+		result := service.store.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.Config{
+			Name:  name,
+			Value: string(value),
+		})
+		if result.Error != nil {
+			return api.ErrDatabaseCause(result.Error, "Failed to create lease")
+		}
+
+		// acquired by others concurrently
+		if result.RowsAffected == 0 {
+			return errors.WithMessagef(ErrLeaseHeld, "lease = %v", name)
+		}
+
+		return nil
+	}
+
+	var current lease
+	if err = json.Unmarshal([]byte(config.Value), &current); err != nil {
+		return errors.WithMessagef(err, "Failed to decode lease %v", config.Value)
+	}
+
+	if current.Owner != owner && now.UnixMilli() < current.ExpiresAt {
+		return errors.WithMessagef(ErrLeaseHeld, "lease = %v, owner = %v, expiresAt = %v", name, current.Owner,
+			time.UnixMilli(current.ExpiresAt).Format(time.RFC3339))
+	}
+
+	// compare and swap, in case of acquired by others concurrently
+	result := service.store.DB.Model(&model.Config{}).
+		Where("name = ? AND value = ?", name, config.Value).
+		Update("value", string(value))
+	if result.Error != nil {
+		return api.ErrDatabaseCause(result.Error, "Failed to update lease")
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.WithMessagef(ErrLeaseHeld, "lease = %v", name)
+	}
+
+	return nil
+}
+
+// Release releases the lease if held by owner.
+func (service *LeaseService) Release(name, owner string) error {
+	var config model.Config
+	found, err := service.store.Get(&config, "name = ?", name)
+	if err != nil {
+		return api.ErrDatabaseCause(err, "Failed to get lease")
+	}
+
+	if !found {
+		return nil
+	}
+
+	var current lease
+	if err = json.Unmarshal([]byte(config.Value), &current); err != nil {
+		return errors.WithMessagef(err, "Failed to decode lease %v", config.Value)
+	}
+
+	if current.Owner != owner {
+		return nil
+	}
+
+	if err = service.store.DB.Delete(&model.Config{}, "name = ? AND value = ?", name, config.Value).Error; err != nil {
+		return api.ErrDatabaseCause(err, "Failed to delete lease")
+	}
+
+	return nil
+}
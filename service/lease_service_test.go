@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestLeaseService(t *testing.T) {
+	service := NewLeaseService(newTestStore(t))
+
+	if err := service.Acquire(LeaseSync, "a", time.Minute); err != nil {
+		t.Fatalf("Failed to acquire lease: %v", err)
+	}
+
+	if err := service.Acquire(LeaseSync, "b", time.Minute); !errors.Is(err, ErrLeaseHeld) {
+		t.Fatalf("Expected ErrLeaseHeld, got %v", err)
+	}
+
+	// renew
+	if err := service.Acquire(LeaseSync, "a", time.Minute); err != nil {
+		t.Fatalf("Failed to renew lease: %v", err)
+	}
+
+	// not released by others
+	if err := service.Release(LeaseSync, "b"); err != nil {
+		t.Fatalf("Failed to release lease: %v", err)
+	}
+
+	if err := service.Acquire(LeaseSync, "b", time.Minute); !errors.Is(err, ErrLeaseHeld) {
+		t.Fatalf("Expected ErrLeaseHeld, got %v", err)
+	}
+
+	if err := service.Release(LeaseSync, "a"); err != nil {
+		t.Fatalf("Failed to release lease: %v", err)
+	}
+
+	if err := service.Acquire(LeaseSync, "b", time.Millisecond); err != nil {
+		t.Fatalf("Failed to acquire released lease: %v", err)
+	}
+
+	// taken over once expired
+	time.Sleep(5 * time.Millisecond)
+
+	if err := service.Acquire(LeaseSync, "a", time.Minute); err != nil {
+		t.Fatalf("Failed to acquire expired lease: %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/v3-Swampy/points-service/blockchain"
+	"github.com/v3-Swampy/points-service/model"
+	"gorm.io/gorm"
+)
+
+// MerkleClaim is the airdrop amount and Merkle proof of an account.
+type MerkleClaim struct {
+	Address string          `json:"address"`
+	Amount  decimal.Decimal `json:"amount"` // amount in the smallest token unit
+	Proof   []common.Hash   `json:"proof"`
+}
+
+type MerkleService struct {
+	store *store.Store
+}
+
+func NewMerkleService(store *store.Store) *MerkleService {
+	return &MerkleService{
+		store: store,
+	}
+}
+
+// Build converts the total points of all users into token amounts by given rate (tokens per point), and builds
+// a sorted Merkle tree over (address, amount) leaves. Claims are returned in order of address.
+//
+// Users are read in a repeatable read transaction, so that points are consistent even if sync applies snapshots
+// concurrently. Besides, addresses are case insensitive, and points of the same address are summed up.
+//
+// Note, the tree promotes the last node of odd layers (see blockchain.NewMerkleTree), so the root is NOT the same as
+// OpenZeppelin's StandardMerkleTree, though proofs could be verified by OpenZeppelin's MerkleProof library.
+func (service *MerkleService) Build(season string, rate decimal.Decimal, decimals uint8) (*model.MerkleDistribution, []MerkleClaim, error) {
+	if !rate.IsPositive() {
+		return nil, nil, errors.New("Rate should be positive")
+	}
+
+	points := make(map[common.Address]decimal.Decimal)
+	if err := service.store.DB.Transaction(func(dbTx *gorm.DB) error {
+		var users []*model.User
+		return dbTx.FindInBatches(&users, 1000, func(tx *gorm.DB, batch int) error {
+			for _, u := range users {
+				if !common.IsHexAddress(u.Address) {
+					return errors.Errorf("Invalid hex address of user %v", u.Address)
+				}
+
+				address := common.HexToAddress(u.Address)
+				points[address] = points[address].Add(u.TradePoints).Add(u.LiquidityPoints).Add(u.ReferralPoints)
+			}
+
+			return nil
+		}).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}); err != nil {
+		return nil, nil, errors.WithMessage(err, "Failed to load users")
+	}
+
+	multiplier := rate.Shift(int32(decimals))
+
+	var claims []MerkleClaim
+	for address, v := range points {
+		if amount := v.Mul(multiplier).Floor(); amount.IsPositive() {
+			claims = append(claims, MerkleClaim{
+				Address: strings.ToLower(address.Hex()),
+				Amount:  amount,
+			})
+		}
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		return claims[i].Address < claims[j].Address
+	})
+
+	total := decimal.Zero
+	leaves := make([]common.Hash, 0, len(claims))
+	for _, v := range claims {
+		total = total.Add(v.Amount)
+		leaves = append(leaves, blockchain.AirdropLeaf(common.HexToAddress(v.Address), v.Amount.BigInt()))
+	}
+
+	tree := blockchain.NewMerkleTree(leaves)
+	for i, v := range leaves {
+		claims[i].Proof, _ = tree.Proof(v)
+	}
+
+	return &model.MerkleDistribution{
+		Season:   season,
+		Root:     tree.Root().Hex(),
+		Rate:     rate,
+		Decimals: decimals,
+		Total:    total,
+		Accounts: len(claims),
+	}, claims, nil
+}
+
+// Save stores the Merkle root and proofs of a season in database.
+//
+// Note, it returns error if the season already exists and overwrite not specified.
+func (service *MerkleService) Save(distribution *model.MerkleDistribution, claims []MerkleClaim, overwrite bool) error {
+	return service.store.DB.Transaction(func(dbTx *gorm.DB) error {
+		var existing model.MerkleDistribution
+		err := dbTx.Where("season = ?", distribution.Season).First(&existing).Error
+		if err == nil && !overwrite {
+			return errors.Errorf("Season %v already exported with root %v", existing.Season, existing.Root)
+		}
+
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.WithMessage(err, "Failed to get Merkle distribution")
+		}
+
+		if err := dbTx.Where("season = ?", distribution.Season).Delete(&model.MerkleDistribution{}).Error; err != nil {
+			return errors.WithMessage(err, "Failed to delete Merkle distribution")
+		}
+
+		if err := dbTx.Where("season = ?", distribution.Season).Delete(&model.MerkleProof{}).Error; err != nil {
+			return errors.WithMessage(err, "Failed to delete Merkle proofs")
+		}
+
+		if err := dbTx.Create(distribution).Error; err != nil {
+			return errors.WithMessage(err, "Failed to create Merkle distribution")
+		}
+
+		if len(claims) == 0 {
+			return nil
+		}
+
+		proofs := make([]*model.MerkleProof, 0, len(claims))
+		for _, v := range claims {
+			encoded, err := json.Marshal(v.Proof)
+			if err != nil {
+				return errors.WithMessage(err, "Failed to marshal Merkle proof")
+			}
+
+			proofs = append(proofs, &model.MerkleProof{
+				Season:  distribution.Season,
+				Address: v.Address,
+				Amount:  v.Amount,
+				Proof:   string(encoded),
+			})
+		}
+
+		if err := dbTx.CreateInBatches(proofs, 500).Error; err != nil {
+			return errors.WithMessage(err, "Failed to create Merkle proofs")
+		}
+
+		return nil
+	})
+}
+
+// GetProof returns the Merkle proof of given address in a season, which defaults to the latest season if empty.
+func (service *MerkleService) GetProof(season, address string) (*model.MerkleProofInfo, error) {
+	var distribution model.MerkleDistribution
+
+	db := service.store.DB
+	if len(season) > 0 {
+		db = db.Where("season = ?", season)
+	}
+
+	if err := db.Order("id DESC").First(&distribution).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, api.ErrValidationStr("Failed to find Merkle distribution by season")
+	} else if err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get Merkle distribution by season")
+	}
+
+	var proof model.MerkleProof
+	found, err := service.store.Get(&proof, "season = ? AND address = ?", distribution.Season, strings.ToLower(address))
+	if err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get Merkle proof by address")
+	}
+
+	if !found {
+		return nil, api.ErrValidationStr("Failed to find Merkle proof by address")
+	}
+
+	info := model.MerkleProofInfo{
+		Season:  distribution.Season,
+		Root:    distribution.Root,
+		Address: proof.Address,
+		Amount:  proof.Amount,
+	}
+
+	if err := json.Unmarshal([]byte(proof.Proof), &info.Proof); err != nil {
+		return nil, api.ErrInternal(errors.WithMessage(err, "Failed to unmarshal Merkle proof"))
+	}
+
+	return &info, nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/v3-Swampy/points-service/blockchain"
+	"github.com/v3-Swampy/points-service/model"
+)
+
+func TestMerkleServiceBuild(t *testing.T) {
+	s := newTestStore(t)
+
+	checksummed := "0xAbCdEf0000000000000000000000000000000001"
+	users := []*model.User{
+		{Address: strings.ToLower(checksummed), TradePoints: decimal.NewFromInt(1)},
+		{Address: checksummed, LiquidityPoints: decimal.NewFromInt(2), ReferralPoints: decimal.NewFromInt(3)},
+		{Address: testReferee, TradePoints: decimal.NewFromInt(10)},
+		{Address: testReferrer}, // no points
+	}
+	if err := s.DB.Create(users).Error; err != nil {
+		t.Fatalf("Failed to create users: %v", err)
+	}
+
+	distribution, claims, err := NewMerkleService(s).Build("s1", decimal.NewFromInt(2), 0)
+	if err != nil {
+		t.Fatalf("Failed to build Merkle tree: %v", err)
+	}
+
+	// points of the same address in different case are summed up
+	expected := []MerkleClaim{
+		{Address: testReferee, Amount: decimal.NewFromInt(20)},
+		{Address: strings.ToLower(checksummed), Amount: decimal.NewFromInt(12)},
+	}
+
+	if len(claims) != len(expected) || distribution.Accounts != len(expected) {
+		t.Fatalf("Expected %v claims, got %+v", len(expected), claims)
+	}
+
+	if !distribution.Total.Equal(decimal.NewFromInt(32)) {
+		t.Fatalf("Expected total 32, got %v", distribution.Total)
+	}
+
+	for i, v := range expected {
+		if claims[i].Address != v.Address || !claims[i].Amount.Equal(v.Amount) {
+			t.Fatalf("Expected claim %+v at %v, got %+v", v, i, claims[i])
+		}
+
+		leaf := blockchain.AirdropLeaf(common.HexToAddress(v.Address), v.Amount.BigInt())
+		if !blockchain.VerifyMerkleProof(claims[i].Proof, common.HexToHash(distribution.Root), leaf) {
+			t.Fatalf("Invalid Merkle proof of %v", v.Address)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/v3-Swampy/points-service/blockchain"
+	"github.com/v3-Swampy/points-service/model"
+	"gorm.io/gorm/clause"
+)
+
+// MetadataCacheService is the database store of on-chain metadata cache, which implements blockchain.CacheStore.
+type MetadataCacheService struct {
+	store *store.Store
+}
+
+var _ blockchain.CacheStore = (*MetadataCacheService)(nil)
+
+func NewMetadataCacheService(store *store.Store) *MetadataCacheService {
+	return &MetadataCacheService{
+		store: store,
+	}
+}
+
+// Load implements the blockchain.CacheStore interface.
+func (service *MetadataCacheService) Load(namespace, key string) ([]byte, bool, error) {
+	var cache model.MetadataCache
+	found, err := service.store.Get(&cache, "namespace = ? AND cache_key = ?", namespace, key)
+	if err != nil || !found {
+		return nil, false, err
+	}
+
+	return []byte(cache.Value), true, nil
+}
+
+// Save implements the blockchain.CacheStore interface.
+func (service *MetadataCacheService) Save(namespace, key string, value []byte) error {
+	now := time.Now()
+
+	return service.store.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "namespace"}, {Name: "cache_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&model.MetadataCache{
+		Namespace: namespace,
+		Key:       key,
+		Value:     string(value),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}).Error
+}
+
+// Clear deletes the cached metadata of given keys in namespace, and returns the number of deleted entries.
+// If no key specified, all cached metadata in namespace will be deleted.
+func (service *MetadataCacheService) Clear(namespace string, keys ...string) (int64, error) {
+	db := service.store.DB.Where("namespace = ?", namespace)
+	if len(keys) > 0 {
+		db = db.Where("cache_key IN ?", keys)
+	}
+
+	result := db.Delete(&model.MetadataCache{})
+	if result.Error != nil {
+		return 0, api.ErrDatabaseCause(result.Error, "Failed to clear metadata cache")
+	}
+
+	return result.RowsAffected, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"github.com/v3-Swampy/points-service/model"
+	"gorm.io/gorm"
 )
 
 type PoolParamService struct {
@@ -55,9 +56,14 @@ func (service *PoolParamService) GetOrDefault(pool string, defaultValue model.Po
 	return bean, nil
 }
 
-func (service *PoolParamService) Upsert(pool string, tradeWeight, liquidityWeight decimal.Decimal) error {
+func (service *PoolParamService) Upsert(pool string, tradeWeight, liquidityWeight decimal.Decimal, dbTx ...*gorm.DB) error {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
 	var param model.PoolParams
-	found, err := service.store.Get(&param, "address = ?", pool)
+	found, err := store.NewStore(db).Get(&param, "address = ?", pool)
 	if err != nil {
 		return api.ErrDatabaseCause(err, "Failed to get pool param values by address")
 	}
@@ -68,7 +74,7 @@ func (service *PoolParamService) Upsert(pool string, tradeWeight, liquidityWeigh
 			TradeWeight:     tradeWeight,
 			LiquidityWeight: liquidityWeight,
 		}
-		return service.store.DB.Create(bean).Error
+		return db.Create(bean).Error
 	}
 
 	newParam := map[string]any{}
@@ -79,7 +85,7 @@ func (service *PoolParamService) Upsert(pool string, tradeWeight, liquidityWeigh
 		newParam["liquidity_weight"] = liquidityWeight
 	}
 
-	return service.store.DB.Model(&model.PoolParams{}).
+	return db.Model(&model.PoolParams{}).
 		Where("id = ?", param.ID).
 		Updates(newParam).Error
 }
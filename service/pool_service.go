@@ -2,11 +2,13 @@ package service
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/Conflux-Chain/go-conflux-util/api"
 	"github.com/Conflux-Chain/go-conflux-util/store"
 	"github.com/v3-Swampy/points-service/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PoolService struct {
@@ -25,42 +27,92 @@ func (service *PoolService) BatchDeltaUpsert(pools []*model.Pool, dbTx ...*gorm.
 		db = dbTx[0]
 	}
 
-	var placeholders string
-	var params []interface{}
-	size := len(pools)
-	for i, p := range pools {
-		placeholders += "(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)"
-		if i != size-1 {
-			placeholders += ",\n\t\t\t"
-		}
-		params = append(params, []interface{}{
-			p.Address, p.Token0, p.Token1, p.Fee, p.Tvl, p.TradePoints, p.LiquidityPoints,
-			p.Token0Name, p.Token0Symbol, p.Token0Decimals,
-			p.Token1Name, p.Token1Symbol, p.Token1Decimals,
-			p.CreatedAt, p.UpdatedAt,
-		}...)
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "address"}},
+		DoUpdates: append(
+			accumulateColumns(db, "pools", "trade_points", "liquidity_points"),
+			clause.AssignmentColumns([]string{"token0", "token1", "fee", "tvl", "tvl0", "tvl1", "updated_at"})...,
+		),
+	}).CreateInBatches(pools, upsertBatchSize).Error
+}
+
+// BatchDeltaUpsertPoints accumulates points of existing pools without updating other columns, e.g. TVL, or creates
+// pools if not exist.
+func (service *PoolService) BatchDeltaUpsertPoints(pools []*model.Pool, dbTx ...*gorm.DB) error {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}},
+		DoUpdates: accumulateColumns(db, "pools", "trade_points", "liquidity_points"),
+	}).CreateInBatches(pools, upsertBatchSize).Error
+}
+
+// BatchInsertSnapshots inserts the pool snapshots, and ignores the snapshots that already exist.
+func (service *PoolService) BatchInsertSnapshots(snapshots []*model.PoolSnapshot, dbTx ...*gorm.DB) error {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	return db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(snapshots, upsertBatchSize).Error
+}
+
+// Get returns the pool detail with weights.
+func (service *PoolService) Get(address string) (*model.PoolDetail, error) {
+	var pools []*model.PoolDetail
+
+	if err := service.store.DB.Model(&model.Pool{}).
+		Select("pools.*, pool_params.trade_weight, pool_params.liquidity_weight").
+		Joins("INNER JOIN pool_params ON pools.address = pool_params.address").
+		Where("pools.address = ?", address).
+		Limit(1).
+		Find(&pools).Error; err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get pool by address")
+	}
+
+	if len(pools) == 0 {
+		return nil, api.ErrValidationStr("Failed to find pool by address")
+	}
+
+	return pools[0], nil
+}
+
+// ListTvlHistory returns the latest pool TVL snapshots in time range, which are in ascending order of timestamp.
+func (service *PoolService) ListTvlHistory(address string, request model.HistoryRequest) ([]model.PoolTvlInfo, error) {
+	snapshots, err := service.ListSnapshots(address, request)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.PoolTvlInfo, 0, len(snapshots))
+	for _, v := range snapshots {
+		result = append(result, model.PoolTvlInfo{
+			Timestamp: v.Timestamp,
+			Tvl:       v.Tvl,
+		})
+	}
+
+	return result, nil
+}
+
+// ListSnapshots returns the latest pool snapshots in time range, which are in ascending order of timestamp.
+func (service *PoolService) ListSnapshots(address string, request model.HistoryRequest) ([]*model.PoolSnapshot, error) {
+	db := service.store.DB.Model(&model.PoolSnapshot{}).Where("pool = ? AND timestamp >= ?", address, request.From)
+	if request.To > 0 {
+		db = db.Where("timestamp <= ?", request.To)
+	}
+
+	var snapshots []*model.PoolSnapshot
+	if err := db.Order("timestamp DESC").Limit(request.Limit).Find(&snapshots).Error; err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get pool snapshots")
 	}
 
-	sqlString := fmt.Sprintf(`
-		insert into 
-    		pools(address, token0, token1, fee, tvl, trade_points, liquidity_points, 
-    		      token0_name, token0_symbol, token0_decimals, 
-    		      token1_name, token1_symbol, token1_decimals,
-    		      created_at, updated_at)
-		values
-			%s
-		on duplicate key update
-			address = values(address),
-			token0 = values(token0),
-			token1 = values(token1),
-			fee = values(fee),
-			tvl = values(tvl),
-			trade_points = trade_points + values(trade_points),
-			liquidity_points = liquidity_points + values(liquidity_points),
-			updated_at = values(updated_at)                      
-	`, placeholders)
-
-	return db.Exec(sqlString, params...).Error
+	slices.Reverse(snapshots)
+
+	return snapshots, nil
 }
 
 func (service *PoolService) List(request model.PoolPagingRequest) (total int64, pools []*model.PoolInfo, err error) {
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/v3-Swampy/points-service/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReferralConfig struct {
+	// Rate is the share of points earned by referees that credited to referrer, e.g. 0.1 for 10%.
+	Rate float64
+}
+
+type ReferralService struct {
+	store *store.Store
+}
+
+func NewReferralService(store *store.Store) *ReferralService {
+	return &ReferralService{
+		store: store,
+	}
+}
+
+// Get returns the referral of given referee, or nil if not bound yet.
+func (service *ReferralService) Get(address string) (*model.Referral, error) {
+	var referral model.Referral
+	found, err := service.store.Get(&referral, "address = ?", strings.ToLower(address))
+	if err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get referral by address")
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return &referral, nil
+}
+
+// CountReferees returns the number of referees bound to the given referrer.
+func (service *ReferralService) CountReferees(referrer string) (count int64, err error) {
+	if err = service.store.DB.Model(&model.Referral{}).
+		Where("referrer = ?", strings.ToLower(referrer)).
+		Count(&count).Error; err != nil {
+		return 0, api.ErrDatabaseCause(err, "Failed to count referees")
+	}
+
+	return
+}
+
+// Bind binds the referrer for the given referee, which is allowed only once for any referee.
+func (service *ReferralService) Bind(address, referrer string) (*model.Referral, error) {
+	address, referrer = strings.ToLower(address), strings.ToLower(referrer)
+
+	if address == referrer {
+		return nil, api.ErrValidationStr("Referrer cannot be the referee itself")
+	}
+
+	// avoid referral cycle between two users
+	upstream, err := service.Get(referrer)
+	if err != nil {
+		return nil, err
+	}
+
+	if upstream != nil && upstream.Referrer == address {
+		return nil, api.ErrValidationStr("Referrer is already referred by the referee")
+	}
+
+	referral := &model.Referral{
+		Address:  address,
+		Referrer: referrer,
+	}
+
+	// unique index guarantees that referrer could not be bound twice even concurrently
+	result := service.store.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(referral)
+	if result.Error != nil {
+		return nil, api.ErrDatabaseCause(result.Error, "Failed to create referral")
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, api.ErrValidationStr("Referrer already bound")
+	}
+
+	return referral, nil
+}
+
+// GetReferrers returns the referrers of given referees in lowercase, which is keyed by referee in lowercase.
+func (service *ReferralService) GetReferrers(addresses []string, dbTx ...*gorm.DB) (map[string]string, error) {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	lowerAddresses := make([]string, 0, len(addresses))
+	for _, v := range addresses {
+		lowerAddresses = append(lowerAddresses, strings.ToLower(v))
+	}
+
+	var referrals []*model.Referral
+	if err := db.Where("address IN ?", lowerAddresses).Find(&referrals).Error; err != nil {
+		return nil, err
+	}
+
+	referrers := make(map[string]string, len(referrals))
+	for _, v := range referrals {
+		referrers[strings.ToLower(v.Address)] = strings.ToLower(v.Referrer)
+	}
+
+	return referrers, nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReferralServiceBind(t *testing.T) {
+	service := NewReferralService(newTestStore(t))
+
+	if _, err := service.Bind(testReferee, testReferrer); err != nil {
+		t.Fatalf("Failed to bind referrer: %v", err)
+	}
+
+	// conflicts on unique index of referee
+	_, err := service.Bind(testReferee, testPool.String())
+	if err == nil || !strings.Contains(err.Error(), "Referrer already bound") {
+		t.Fatalf("Expected error of referrer already bound, got %v", err)
+	}
+
+	if _, err = service.Bind(testReferrer, testReferee); err == nil {
+		t.Fatal("Expected error to bind referral cycle")
+	}
+
+	if _, err = service.Bind(testPool.String(), testPool.String()); err == nil {
+		t.Fatal("Expected error to bind referee itself")
+	}
+
+	referral, err := service.Get(testReferee)
+	if err != nil {
+		t.Fatalf("Failed to get referral: %v", err)
+	}
+
+	if referral == nil || referral.Referrer != strings.ToLower(testReferrer) {
+		t.Fatalf("Expected referrer %v, got %+v", testReferrer, referral)
+	}
+}
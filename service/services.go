@@ -6,19 +6,41 @@ import (
 )
 
 type Services struct {
-	Config    *ConfigService
-	PoolParam *PoolParamService
-	Pool      *PoolService
-	User      *UserService
-	Stat      *StatService
+	Config     *ConfigService
+	PoolParam  *PoolParamService
+	Pool       *PoolService
+	Token      *TokenService
+	User       *UserService
+	Referral   *ReferralService
+	Merkle     *MerkleService
+	Audit      *AuditService
+	Stat       *StatService
+	Checkpoint *CheckpointService
+	Lease      *LeaseService
+	Skipped    *SkippedService
+
+	SignedRequest *SignedRequestService
 }
 
-func NewServices(store *store.Store, vswap *blockchain.Vswap) Services {
+func NewServices(store *store.Store, vswap *blockchain.Vswap, config PointsConfig, concurrency int) Services {
+	poolParam := NewPoolParamService(store)
+	user := NewUserService(store, config.Precision)
+	token := NewTokenService(store)
+
 	return Services{
-		Config:    NewConfigService(store),
-		PoolParam: NewPoolParamService(store),
-		Pool:      NewPoolService(store),
-		User:      NewUserService(store),
-		Stat:      NewStatService(store, vswap),
+		Config:     NewConfigService(store),
+		PoolParam:  poolParam,
+		Pool:       NewPoolService(store),
+		Token:      token,
+		User:       user,
+		Referral:   NewReferralService(store),
+		Merkle:     NewMerkleService(store),
+		Audit:      NewAuditService(store, user, poolParam, token),
+		Stat:       NewStatService(store, vswap, config, concurrency),
+		Checkpoint: NewCheckpointService(store),
+		Lease:      NewLeaseService(store),
+		Skipped:    NewSkippedService(store),
+
+		SignedRequest: NewSignedRequestService(store),
 	}
 }
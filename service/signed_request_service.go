@@ -0,0 +1,53 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/v3-Swampy/points-service/model"
+	"gorm.io/gorm/clause"
+)
+
+type SignedRequestService struct {
+	store *store.Store
+}
+
+func NewSignedRequestService(store *store.Store) *SignedRequestService {
+	return &SignedRequestService{
+		store: store,
+	}
+}
+
+// Consume marks the nonce of signer as used, and returns validation error if the nonce already used.
+//
+// Note, consumed nonces of the signer are pruned once expired, since expired requests will be rejected anyway.
+func (service *SignedRequestService) Consume(signer string, nonce uint64, deadline int64, method, path string) error {
+	signer = strings.ToLower(signer)
+
+	if err := service.store.DB.
+		Where("signer = ? AND deadline < ?", signer, time.Now().Unix()).
+		Delete(&model.SignedRequest{}).Error; err != nil {
+		return api.ErrDatabaseCause(err, "Failed to prune expired nonces")
+	}
+
+	// insert directly, and unique index guarantees that nonce could not be consumed twice even concurrently
+	result := service.store.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&model.SignedRequest{
+		Signer:   signer,
+		Nonce:    nonce,
+		Deadline: deadline,
+		Method:   method,
+		Path:     path,
+	})
+	if result.Error != nil {
+		return api.ErrDatabaseCause(result.Error, "Failed to consume nonce")
+	}
+
+	// conflicts on unique index (signer, nonce) if already used
+	if result.RowsAffected == 0 {
+		return api.ErrValidationStrf("Nonce %v already used", nonce)
+	}
+
+	return nil
+}
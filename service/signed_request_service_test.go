@@ -0,0 +1,31 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignedRequestServiceConsume(t *testing.T) {
+	service := NewSignedRequestService(newTestStore(t))
+	deadline := time.Now().Add(time.Minute).Unix()
+
+	if err := service.Consume(testReferrer, 1, deadline, "POST", "/api/test"); err != nil {
+		t.Fatalf("Failed to consume nonce: %v", err)
+	}
+
+	// replayed
+	err := service.Consume(testReferrer, 1, deadline, "POST", "/api/test")
+	if err == nil || !strings.Contains(err.Error(), "Nonce 1 already used") {
+		t.Fatalf("Expected error of used nonce, got %v", err)
+	}
+
+	// nonce is scoped by signer
+	if err := service.Consume(testReferee, 1, deadline, "POST", "/api/test"); err != nil {
+		t.Fatalf("Failed to consume nonce of another signer: %v", err)
+	}
+
+	if err := service.Consume(testReferrer, 2, deadline, "POST", "/api/test"); err != nil {
+		t.Fatalf("Failed to consume another nonce: %v", err)
+	}
+}
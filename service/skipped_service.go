@@ -0,0 +1,115 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/v3-Swampy/points-service/model"
+	"github.com/v3-Swampy/points-service/sync"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SkippedService records events of pools that skipped in applied snapshots, e.g. pool quarantined, so that they
+// could be replayed later rather than lost.
+type SkippedService struct {
+	store *store.Store
+}
+
+func NewSkippedService(store *store.Store) *SkippedService {
+	return &SkippedService{
+		store: store,
+	}
+}
+
+// BatchInsert records the skipped pools, and ignores those already recorded. It should be called in the same
+// transaction that applies snapshots.
+func (service *SkippedService) BatchInsert(skipped []sync.SkippedPool, dbTx ...*gorm.DB) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	now := time.Now()
+	records := make([]*model.SkippedSnapshot, 0, len(skipped))
+	for _, v := range skipped {
+		reason := v.Reason
+		if len(reason) > 1024 {
+			reason = reason[:1024]
+		}
+
+		records = append(records, &model.SkippedSnapshot{
+			Pool:           v.Pool.String(),
+			Timestamp:      v.Timestamp,
+			MinBlockNumber: v.MinBlockNumber,
+			MaxBlockNumber: v.MaxBlockNumber,
+			Trades:         v.Trades,
+			Liquidities:    v.Liquidities,
+			Reason:         reason,
+			CreatedAt:      now,
+		})
+	}
+
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(records, upsertBatchSize).Error; err != nil {
+		return api.ErrDatabaseCause(err, "Failed to record skipped snapshots")
+	}
+
+	return nil
+}
+
+// ListPending returns skipped snapshots of pools that not replayed yet, in order of timestamp and pool.
+func (service *SkippedService) ListPending(limit int) ([]sync.SkippedPool, error) {
+	var records []*model.SkippedSnapshot
+	if err := service.store.DB.Where("replayed_at IS NULL").
+		Order("timestamp ASC").Order("pool ASC").
+		Limit(limit).
+		Find(&records).Error; err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get pending skipped snapshots")
+	}
+
+	result := make([]sync.SkippedPool, 0, len(records))
+	for _, v := range records {
+		result = append(result, sync.SkippedPool{
+			TimeInfo: sync.TimeInfo{
+				Timestamp:      v.Timestamp,
+				MinBlockNumber: v.MinBlockNumber,
+				MaxBlockNumber: v.MaxBlockNumber,
+			},
+			Pool:        common.HexToAddress(v.Pool),
+			Trades:      v.Trades,
+			Liquidities: v.Liquidities,
+			Reason:      v.Reason,
+		})
+	}
+
+	return result, nil
+}
+
+// MarkReplayed marks the skipped snapshot of pool as replayed, and returns sync.ErrReplayed if already replayed
+// or not recorded. It should be called in the same transaction that applies replayed events.
+func (service *SkippedService) MarkReplayed(skipped sync.SkippedPool, dbTx ...*gorm.DB) error {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	result := db.Model(&model.SkippedSnapshot{}).
+		Where("pool = ? AND timestamp = ? AND replayed_at IS NULL", skipped.Pool.String(), skipped.Timestamp).
+		Update("replayed_at", time.Now())
+	if result.Error != nil {
+		return api.ErrDatabaseCause(result.Error, "Failed to mark skipped snapshot replayed")
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.WithMessagef(sync.ErrReplayed, "pool = %v, timestamp = %v", skipped.Pool, skipped.Timestamp)
+	}
+
+	return nil
+}
@@ -1,45 +1,82 @@
 package service
 
 import (
-	"math/big"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/Conflux-Chain/go-conflux-util/store"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
 	"github.com/v3-Swampy/points-service/blockchain"
 	"github.com/v3-Swampy/points-service/model"
 	"github.com/v3-Swampy/points-service/sync"
+	"golang.org/x/sync/errgroup"
 	"gorm.io/gorm"
 )
 
 var pointsPerValueSecond = decimal.NewFromFloat(0.1 / 3600)
 
+type PointsConfig struct {
+	Precision PrecisionConfig
+	Referral  ReferralConfig
+}
+
 type StatService struct {
 	store *store.Store
 
-	config *ConfigService
-	param  *PoolParamService
-	user   *UserService
-	pool   *PoolService
+	config     *ConfigService
+	param      *PoolParamService
+	user       *UserService
+	pool       *PoolService
+	referral   *ReferralService
+	token      *TokenService
+	checkpoint *CheckpointService
+	skipped    *SkippedService
+
+	vswap        *blockchain.Vswap
+	concurrency  int // maximum number of blocks to query TVL concurrently
+	referralRate decimal.Decimal
 
-	vswap *blockchain.Vswap
+	// queries the TVL of token0 and token1 of pools at block, which defaults to query via vswap
+	tvl func(bn uint64, pools []common.Address) ([][2]decimal.Decimal, error)
 }
 
-func NewStatService(store *store.Store, vswap *blockchain.Vswap) *StatService {
-	return &StatService{
-		store:  store,
-		config: NewConfigService(store),
-		param:  NewPoolParamService(store),
-		user:   NewUserService(store),
-		pool:   NewPoolService(store),
-		vswap:  vswap,
+func NewStatService(store *store.Store, vswap *blockchain.Vswap, config PointsConfig, concurrency int) *StatService {
+	service := &StatService{
+		store:        store,
+		config:       NewConfigService(store),
+		param:        NewPoolParamService(store),
+		user:         NewUserService(store, config.Precision),
+		pool:         NewPoolService(store),
+		referral:     NewReferralService(store),
+		token:        NewTokenService(store),
+		checkpoint:   NewCheckpointService(store),
+		skipped:      NewSkippedService(store),
+		vswap:        vswap,
+		concurrency:  max(concurrency, 1),
+		referralRate: decimal.NewFromFloat(config.Referral.Rate),
 	}
+
+	service.tvl = service.queryTVL
+
+	return service
 }
 
 func (service *StatService) OnEventBatch(event sync.BatchEvent) error {
+	event, ok, err := service.excludeApplied(event)
+	if err != nil {
+		return err
+	}
+
+	// all snapshots already applied, e.g. delivered twice
+	if !ok {
+		return nil
+	}
+
 	users := make(map[string]*model.User)
 	pools := make(map[string]*model.Pool)
 
@@ -51,18 +88,114 @@ func (service *StatService) OnEventBatch(event sync.BatchEvent) error {
 		return err
 	}
 
-	if err := service.aggregateTVL(event.TimeInfo, pools); err != nil {
+	snapshots, err := service.aggregateSnapshots(event, pools)
+	if err != nil {
 		return err
 	}
 
-	return service.Store(event.Timestamp, users, pools)
+	return service.Store(event.Snapshots, users, pools, snapshots, event.Prices, event.Skipped)
+}
+
+// OnReplayBatch applies events of pool that skipped in an applied snapshot, e.g. pool quarantined, and returns
+// sync.ErrReplayed if already replayed. Note, the TVL of existing pool is not updated, since replayed snapshot
+// is older than the latest one.
+func (service *StatService) OnReplayBatch(skipped sync.SkippedPool, event sync.BatchEvent) error {
+	users := make(map[string]*model.User)
+	pools := make(map[string]*model.Pool)
+
+	if err := service.aggregateTrade(event.Trades, users, pools); err != nil {
+		return err
+	}
+
+	if err := service.aggregateLiquidity(event.Liquidities, users, pools); err != nil {
+		return err
+	}
+
+	snapshots, err := service.aggregateSnapshots(event, pools)
+	if err != nil {
+		return err
+	}
+
+	return service.store.DB.Transaction(func(dbTx *gorm.DB) error {
+		// reject snapshot of pool that already replayed, so that events will be applied exactly once
+		if err := service.skipped.MarkReplayed(skipped, dbTx); err != nil {
+			return err
+		}
+
+		return service.storePoints(users, pools, snapshots, event.Prices, false, dbTx)
+	})
+}
+
+// excludeApplied removes events of snapshots that already applied, and the batch time info is set to the last
+// snapshot that not applied yet. It returns false if all snapshots already applied.
+func (service *StatService) excludeApplied(event sync.BatchEvent) (sync.BatchEvent, bool, error) {
+	if len(event.Snapshots) == 0 {
+		event.Snapshots = []sync.TimeInfo{event.TimeInfo}
+	}
+
+	timestamps := make([]int64, 0, len(event.Snapshots))
+	for _, v := range event.Snapshots {
+		timestamps = append(timestamps, v.Timestamp)
+	}
+
+	applied, err := service.checkpoint.Applied(timestamps)
+	if err != nil {
+		return sync.BatchEvent{}, false, err
+	}
+
+	if len(applied) == 0 {
+		return event, true, nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"snapshots": len(event.Snapshots),
+		"applied":   len(applied),
+	}).Warn("Skip snapshots that already applied")
+
+	var result sync.BatchEvent
+	for _, v := range event.Snapshots {
+		if !applied[v.Timestamp] {
+			result.TimeInfo = v
+			result.Snapshots = append(result.Snapshots, v)
+		}
+	}
+
+	if len(result.Snapshots) == 0 {
+		return sync.BatchEvent{}, false, nil
+	}
+
+	for _, v := range event.Trades {
+		if !applied[v.Timestamp] {
+			result.Trades = append(result.Trades, v)
+		}
+	}
+
+	for _, v := range event.Liquidities {
+		if !applied[v.Timestamp] {
+			result.Liquidities = append(result.Liquidities, v)
+		}
+	}
+
+	for _, v := range event.Prices {
+		if !applied[v.Timestamp] {
+			result.Prices = append(result.Prices, v)
+		}
+	}
+
+	for _, v := range event.Skipped {
+		if !applied[v.Timestamp] {
+			result.Skipped = append(result.Skipped, v)
+		}
+	}
+
+	return result, true, nil
 }
 
 func (service *StatService) aggregateTrade(event []sync.TradeEvent, users map[string]*model.User,
 	pools map[string]*model.Pool) error {
 	for _, trade := range event {
 		statTime := time.Unix(trade.Timestamp, 0)
-		user := trade.User
+		user := strings.ToLower(trade.User) // parser may return addresses in mixed case
 		pool := trade.Pool.Address.String()
 
 		weight, err := service.param.Get(pool)
@@ -93,7 +226,7 @@ func (service *StatService) aggregateLiquidity(event []sync.LiquidityEvent, user
 	pools map[string]*model.Pool) error {
 	for _, liquidity := range event {
 		statTime := time.Unix(liquidity.Timestamp, 0)
-		user := liquidity.User
+		user := strings.ToLower(liquidity.User) // parser may return addresses in mixed case
 		pool := liquidity.Pool.Address.String()
 
 		weight, err := service.param.Get(pool)
@@ -120,49 +253,305 @@ func (service *StatService) aggregateLiquidity(event []sync.LiquidityEvent, user
 	return nil
 }
 
-func (service *StatService) aggregateTVL(timeInfo sync.TimeInfo, pools map[string]*model.Pool) error {
-	opts := bind.CallOpts{
-		BlockNumber: new(big.Int).SetUint64(timeInfo.MaxBlockNumber),
+type poolSnapshotKey struct {
+	pool      string
+	timestamp int64
+}
+
+// aggregateSnapshots aggregates the pool metrics of each snapshot in batch, and updates the latest TVL of pools.
+// Snapshots are returned in order of timestamp and pool.
+func (service *StatService) aggregateSnapshots(event sync.BatchEvent, pools map[string]*model.Pool) ([]*model.PoolSnapshot, error) {
+	snapshots := make(map[poolSnapshotKey]*model.PoolSnapshot)
+	traders := make(map[poolSnapshotKey]map[string]bool)
+	providers := make(map[poolSnapshotKey]map[string]bool)
+
+	getOrCreate := func(e sync.PoolEvent) (poolSnapshotKey, *model.PoolSnapshot) {
+		key := poolSnapshotKey{e.Pool.Address.String(), e.Timestamp}
+		if _, ok := snapshots[key]; !ok {
+			snapshots[key] = &model.PoolSnapshot{
+				Pool:      key.pool,
+				Timestamp: key.timestamp,
+				Price0:    e.Price0,
+				Price1:    e.Price1,
+			}
+			traders[key] = make(map[string]bool)
+			providers[key] = make(map[string]bool)
+		}
+
+		return key, snapshots[key]
+	}
+
+	for _, trade := range event.Trades {
+		key, snapshot := getOrCreate(trade.PoolEvent)
+		snapshot.VolumeUsd = snapshot.VolumeUsd.Add(trade.Value0.Add(trade.Value1).Div(decimal.NewFromInt(2)))
+		traders[key][strings.ToLower(trade.User)] = true
+	}
+
+	for _, liquidity := range event.Liquidities {
+		key, snapshot := getOrCreate(liquidity.PoolEvent)
+		snapshot.LiquidityValueSeconds = snapshot.LiquidityValueSeconds.Add(liquidity.Value0Seconds.Add(liquidity.Value1Seconds))
+		providers[key][strings.ToLower(liquidity.User)] = true
+	}
+
+	// max block number of each snapshot to query TVL
+	blockNumbers := make(map[int64]uint64, len(event.Snapshots)+1)
+	for _, v := range event.Snapshots {
+		blockNumbers[v.Timestamp] = v.MaxBlockNumber
+	}
+	blockNumbers[event.Timestamp] = event.MaxBlockNumber
+
+	snapshotBlockNumber := func(timestamp int64) uint64 {
+		if bn, ok := blockNumbers[timestamp]; ok {
+			return bn
+		}
+
+		return event.MaxBlockNumber
+	}
+
+	// TVL of snapshots, and TVL of pools at the end of batch
+	requests := make(map[uint64][]string)
+	for key := range snapshots {
+		bn := snapshotBlockNumber(key.timestamp)
+		requests[bn] = append(requests[bn], key.pool)
+	}
+
+	for address := range pools {
+		requests[event.MaxBlockNumber] = append(requests[event.MaxBlockNumber], address)
+	}
+
+	tvls, err := service.aggregateTVL(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*model.PoolSnapshot, 0, len(snapshots))
+	for key, snapshot := range snapshots {
+		tvl := tvls[poolTvlKey{key.pool, snapshotBlockNumber(key.timestamp)}]
+
+		snapshot.Tvl = tvl[0].Add(tvl[1])
+		snapshot.Traders = len(traders[key])
+		snapshot.LiquidityProviders = len(providers[key])
+
+		result = append(result, snapshot)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Timestamp != result[j].Timestamp {
+			return result[i].Timestamp < result[j].Timestamp
+		}
+
+		return result[i].Pool < result[j].Pool
+	})
+
+	// update pools TVL at the end of batch
+	for address, pool := range pools {
+		tvl := tvls[poolTvlKey{address, event.MaxBlockNumber}]
+		pool.Tvl, pool.Tvl0, pool.Tvl1 = tvl[0].Add(tvl[1]), tvl[0], tvl[1]
+	}
+
+	return result, nil
+}
+
+type poolTvlKey struct {
+	pool        string
+	blockNumber uint64
+}
+
+// aggregateTVL queries the TVL of token0 and token1 of pools at each block, in which reads at the same block are
+// aggregated into a single request, and blocks are queried concurrently.
+func (service *StatService) aggregateTVL(requests map[uint64][]string) (map[poolTvlKey][2]decimal.Decimal, error) {
+	blockNumbers := make([]uint64, 0, len(requests))
+	for bn := range requests {
+		blockNumbers = append(blockNumbers, bn)
 	}
+	slices.Sort(blockNumbers)
+
+	tvls := make([][][2]decimal.Decimal, len(blockNumbers))
+	pools := make([][]common.Address, len(blockNumbers))
+
+	var group errgroup.Group
+	group.SetLimit(service.concurrency)
+	for i, bn := range blockNumbers {
+		// pool may be requested by both snapshot and batch end
+		seen := make(map[string]bool)
+		for _, v := range requests[bn] {
+			if !seen[v] {
+				seen[v] = true
+				pools[i] = append(pools[i], common.HexToAddress(v))
+			}
+		}
 
+		group.Go(func() (err error) {
+			tvls[i], err = service.tvl(bn, pools[i])
+			return err
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[poolTvlKey][2]decimal.Decimal)
+	for i, bn := range blockNumbers {
+		for j, pool := range pools[i] {
+			result[poolTvlKey{pool.String(), bn}] = tvls[i][j]
+		}
+	}
+
+	return result, nil
+}
+
+// queryTVL queries the TVL of token0 and token1 of pools at the specified block, and returns in order of pools.
+func (service *StatService) queryTVL(bn uint64, pools []common.Address) ([][2]decimal.Decimal, error) {
+	reader := service.vswap.NewBlockReader(bn)
+
+	// reads will be requested one by one if failed to prefetch
+	if err := reader.Prefetch(pools, nil); err != nil {
+		logrus.WithError(err).WithField("bn", bn).Warn("Failed to prefetch pool TVL")
+	}
+
+	result := make([][2]decimal.Decimal, 0, len(pools))
 	for _, pool := range pools {
-		tvl, err := service.vswap.GetPoolTVL(&opts, common.HexToAddress(pool.Address))
+		tvl0, tvl1, err := reader.Vswap.GetPoolTokenTVL(reader.Opts(), pool)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		result = append(result, [2]decimal.Decimal{tvl0, tvl1})
+	}
+
+	return result, nil
+}
+
+// aggregateReferral credits referral points to referrers of the given users, which share a
+// configured rate of the trade and liquidity points earned by referees.
+func (service *StatService) aggregateReferral(users map[string]*model.User, dbTx *gorm.DB) error {
+	if !service.referralRate.IsPositive() || len(users) == 0 {
+		return nil
+	}
+
+	// users are keyed by lowercase address, the same as referrers
+	referees := make([]*model.User, 0, len(users))
+	addresses := make([]string, 0, len(users))
+	for address, user := range users {
+		referees = append(referees, user)
+		addresses = append(addresses, address)
+	}
+
+	referrers, err := service.referral.GetReferrers(addresses, dbTx)
+	if err != nil {
+		return errors.WithMessage(err, "failed to get referrers")
+	}
+
+	for _, referee := range referees {
+		referrer, ok := referrers[referee.Address]
+		if !ok {
+			continue
 		}
 
-		pool.Tvl = tvl
+		bonus := referee.TradePoints.Add(referee.LiquidityPoints).Mul(service.referralRate)
+		if !bonus.IsPositive() {
+			continue
+		}
+
+		if u, exists := users[referrer]; exists {
+			u.ReferralPoints = u.ReferralPoints.Add(bonus)
+		} else {
+			u = model.NewUser(referrer, decimal.Zero, decimal.Zero, referee.UpdatedAt)
+			u.ReferralPoints = bonus
+			users[referrer] = u
+		}
 	}
 
 	return nil
 }
 
-func (service *StatService) Store(timestamp int64, users map[string]*model.User, pools map[string]*model.Pool) error {
+// Store applies the aggregated points of snapshots in a single transaction, and returns ErrSnapshotApplied if any
+// snapshot already applied. Pools skipped in snapshots are recorded in the same transaction, so that they could be
+// replayed later.
+func (service *StatService) Store(applied []sync.TimeInfo, users map[string]*model.User, pools map[string]*model.Pool,
+	snapshots []*model.PoolSnapshot, prices []sync.TokenPrice, skipped []sync.SkippedPool) error {
+	if len(applied) == 0 {
+		return errors.New("No snapshot to apply")
+	}
+
 	return service.store.DB.Transaction(func(dbTx *gorm.DB) error {
-		if len(users) > 0 {
-			userArray := make([]*model.User, 0, len(users))
-			for _, user := range users {
-				userArray = append(userArray, user)
-			}
-			if err := service.user.BatchDeltaUpsert(userArray, dbTx); err != nil {
-				return errors.WithMessage(err, "failed to batch delta upsert users")
-			}
+		// reject snapshots that already applied, so that any snapshot will be applied exactly once
+		if err := service.checkpoint.MarkApplied(applied, dbTx); err != nil {
+			return err
 		}
 
-		if len(pools) > 0 {
-			poolArray := make([]*model.Pool, 0, len(pools))
-			for _, pool := range pools {
-				poolArray = append(poolArray, pool)
-			}
-			if err := service.pool.BatchDeltaUpsert(poolArray, dbTx); err != nil {
-				return errors.WithMessage(err, "failed to batch delta upsert pools")
-			}
+		if err := service.skipped.BatchInsert(skipped, dbTx); err != nil {
+			return err
+		}
+
+		if err := service.storePoints(users, pools, snapshots, prices, true, dbTx); err != nil {
+			return err
 		}
 
-		if err := service.config.UpsertLastStatPointsTime(timestamp, dbTx); err != nil {
+		last := applied[len(applied)-1]
+		if err := service.config.UpsertLastStatPointsTime(last.Timestamp, dbTx); err != nil {
+			return err
+		}
+
+		// block range of the last snapshot, so that sync resumes with the next contiguous block window
+		if err := service.checkpoint.Upsert(CheckpointStageStat, last, dbTx); err != nil {
 			return err
 		}
 
 		return nil
 	})
 }
+
+// storePoints stores the aggregated points of users and pools along with referral points, pool snapshots and token
+// prices, where TVL of existing pools is updated only if updateTvl is true.
+func (service *StatService) storePoints(users map[string]*model.User, pools map[string]*model.Pool,
+	snapshots []*model.PoolSnapshot, prices []sync.TokenPrice, updateTvl bool, dbTx *gorm.DB) error {
+	if err := service.aggregateReferral(users, dbTx); err != nil {
+		return err
+	}
+
+	if len(users) > 0 {
+		userArray := make([]*model.User, 0, len(users))
+		for _, user := range users {
+			userArray = append(userArray, user)
+		}
+		if err := service.user.BatchDeltaUpsert(userArray, dbTx); err != nil {
+			return errors.WithMessage(err, "failed to batch delta upsert users")
+		}
+	}
+
+	if len(pools) > 0 {
+		poolArray := make([]*model.Pool, 0, len(pools))
+		for _, pool := range pools {
+			poolArray = append(poolArray, pool)
+		}
+
+		upsert := service.pool.BatchDeltaUpsert
+		if !updateTvl {
+			upsert = service.pool.BatchDeltaUpsertPoints
+		}
+
+		if err := upsert(poolArray, dbTx); err != nil {
+			return errors.WithMessage(err, "failed to batch delta upsert pools")
+		}
+
+		if err := service.token.BatchUpsert(poolArray, dbTx); err != nil {
+			return errors.WithMessage(err, "failed to batch upsert tokens")
+		}
+	}
+
+	if len(snapshots) > 0 {
+		if err := service.pool.BatchInsertSnapshots(snapshots, dbTx); err != nil {
+			return errors.WithMessage(err, "failed to batch insert pool snapshots")
+		}
+	}
+
+	if len(prices) > 0 {
+		if err := service.token.BatchInsertPrices(prices, dbTx); err != nil {
+			return errors.WithMessage(err, "failed to batch insert token prices")
+		}
+	}
+
+	return nil
+}
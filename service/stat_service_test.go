@@ -0,0 +1,339 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/v3-Swampy/points-service/blockchain"
+	"github.com/v3-Swampy/points-service/model"
+	"github.com/v3-Swampy/points-service/sync"
+	"gorm.io/gorm"
+)
+
+var (
+	testPool     = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testReferee  = "0x2000000000000000000000000000000000000002"
+	testReferrer = "0x3000000000000000000000000000000000000003"
+)
+
+func newTestStatService(t *testing.T) *StatService {
+	t.Helper()
+
+	s := newTestStore(t)
+
+	if err := NewPoolParamService(s).Upsert(testPool.String(), decimal.NewFromInt(1), decimal.NewFromInt(1)); err != nil {
+		t.Fatalf("Failed to add pool params: %v", err)
+	}
+
+	if _, err := NewReferralService(s).Bind(testReferee, testReferrer); err != nil {
+		t.Fatalf("Failed to bind referrer: %v", err)
+	}
+
+	service := NewStatService(s, nil, PointsConfig{
+		Precision: PrecisionConfig{Trade: 0, Liquidity: 1, Rounding: "floor", Referral: 1, ReferralRounding: "floor"},
+		Referral:  ReferralConfig{Rate: 0.1},
+	}, 1)
+
+	// no blockchain in test
+	service.tvl = func(bn uint64, pools []common.Address) ([][2]decimal.Decimal, error) {
+		return make([][2]decimal.Decimal, len(pools)), nil
+	}
+
+	return service
+}
+
+// newTestBatch creates a batch of snapshots, in which the referee trades 100 USD in each snapshot.
+func newTestBatch(timestamps ...int64) sync.BatchEvent {
+	var batch sync.BatchEvent
+
+	for _, ts := range timestamps {
+		info := sync.TimeInfo{Timestamp: ts, MinBlockNumber: uint64(ts) * 10, MaxBlockNumber: uint64(ts)*10 + 9}
+
+		batch.Merge(sync.BatchEvent{
+			TimeInfo:  info,
+			Snapshots: []sync.TimeInfo{info},
+			Trades: []sync.TradeEvent{{
+				PoolEvent: sync.PoolEvent{
+					Timestamp: ts,
+					User:      testReferee,
+					Pool:      blockchain.PoolInfo{PairInfo: blockchain.PairInfo{Address: testPool}},
+				},
+				Value0: decimal.NewFromInt(100),
+				Value1: decimal.NewFromInt(100),
+			}},
+		})
+	}
+
+	return batch
+}
+
+// assertApplied asserts that points of the given number of snapshots are counted exactly once.
+func assertApplied(t *testing.T, service *StatService, snapshots int) {
+	t.Helper()
+
+	db := service.store.DB
+	n := decimal.NewFromInt(int64(snapshots))
+
+	var referee, referrer model.User
+	if err := db.Where("address = ?", testReferee).Take(&referee).Error; err != nil {
+		t.Fatalf("Failed to get referee: %v", err)
+	}
+
+	if err := db.Where("address = ?", testReferrer).Take(&referrer).Error; err != nil {
+		t.Fatalf("Failed to get referrer: %v", err)
+	}
+
+	var pool model.Pool
+	if err := db.Where("address = ?", testPool.String()).Take(&pool).Error; err != nil {
+		t.Fatalf("Failed to get pool: %v", err)
+	}
+
+	var applied int64
+	if err := db.Model(&model.AppliedSnapshot{}).Count(&applied).Error; err != nil {
+		t.Fatalf("Failed to count applied snapshots: %v", err)
+	}
+
+	expected := []struct {
+		name     string
+		actual   decimal.Decimal
+		expected decimal.Decimal
+	}{
+		{"user trade points", referee.TradePoints, n.Mul(decimal.NewFromInt(100))},
+		{"pool trade points", pool.TradePoints, n.Mul(decimal.NewFromInt(100))},
+		{"referral points", referrer.ReferralPoints, n.Mul(decimal.NewFromInt(10))},
+		{"applied snapshots", decimal.NewFromInt(applied), n},
+	}
+
+	for _, v := range expected {
+		if !v.actual.Equal(v.expected) {
+			t.Errorf("Unexpected %v, expected = %v, actual = %v", v.name, v.expected, v.actual)
+		}
+	}
+}
+
+func TestStatServiceRedeliveredBatch(t *testing.T) {
+	service := newTestStatService(t)
+
+	batch := newTestBatch(3600, 7200)
+
+	for i := 0; i < 3; i++ {
+		if err := service.OnEventBatch(batch); err != nil {
+			t.Fatalf("Failed to handle batch: %v", err)
+		}
+	}
+
+	assertApplied(t, service, 2)
+}
+
+func TestStatServiceRetryAfterFailure(t *testing.T) {
+	service := newTestStatService(t)
+
+	// fail the transaction once after snapshots marked applied and users upserted
+	errInjected := errors.New("injected failure")
+	failed := false
+	if err := service.store.DB.Callback().Create().Before("gorm:create").Register("test:fail_pools", func(db *gorm.DB) {
+		if db.Statement.Table == "pools" && !failed {
+			failed = true
+			db.AddError(errInjected)
+		}
+	}); err != nil {
+		t.Fatalf("Failed to register callback: %v", err)
+	}
+
+	batch := newTestBatch(3600, 7200)
+
+	if err := service.OnEventBatch(batch); !errors.Is(err, errInjected) {
+		t.Fatalf("Failure not injected, err = %v", err)
+	}
+
+	assertNothingApplied(t, service)
+
+	if err := service.OnEventBatch(batch); err != nil {
+		t.Fatalf("Failed to retry batch: %v", err)
+	}
+
+	assertApplied(t, service, 2)
+}
+
+func assertNothingApplied(t *testing.T, service *StatService) {
+	t.Helper()
+
+	for _, m := range []any{&model.User{}, &model.Pool{}, &model.AppliedSnapshot{}} {
+		var count int64
+		if err := service.store.DB.Model(m).Count(&count).Error; err != nil {
+			t.Fatalf("Failed to count records: %v", err)
+		}
+
+		if count > 0 {
+			t.Fatalf("Transaction not rolled back, %T records = %v", m, count)
+		}
+	}
+}
+
+func TestStatServiceOverlappedBatch(t *testing.T) {
+	service := newTestStatService(t)
+
+	if err := service.OnEventBatch(newTestBatch(3600, 7200)); err != nil {
+		t.Fatalf("Failed to handle batch: %v", err)
+	}
+
+	// the first two snapshots already applied
+	if err := service.OnEventBatch(newTestBatch(3600, 7200, 10800)); err != nil {
+		t.Fatalf("Failed to handle overlapped batch: %v", err)
+	}
+
+	assertApplied(t, service, 3)
+
+	last, ok, err := service.checkpoint.Get(CheckpointStageStat)
+	if err != nil || !ok || last.Timestamp != 10800 {
+		t.Fatalf("Unexpected checkpoint %+v, found = %v, err = %v", last, ok, err)
+	}
+}
+
+func TestCheckpointServiceMarkApplied(t *testing.T) {
+	service := NewCheckpointService(newTestStore(t))
+
+	snapshots := []sync.TimeInfo{{Timestamp: 3600}, {Timestamp: 7200}}
+	if err := service.MarkApplied(snapshots); err != nil {
+		t.Fatalf("Failed to mark snapshots applied: %v", err)
+	}
+
+	err := service.MarkApplied([]sync.TimeInfo{{Timestamp: 7200}, {Timestamp: 10800}})
+	if !errors.Is(err, ErrSnapshotApplied) {
+		t.Fatalf("Snapshot applied twice, err = %v", err)
+	}
+}
+
+func TestStatServiceMixedCaseAddresses(t *testing.T) {
+	service := newTestStatService(t)
+
+	referee := "0xAaAaAaAaAaAaAaAaAaAaAaAaAaAaAaAaAaAaAaAa"
+	referrer := "0xBbBbBbBbBbBbBbBbBbBbBbBbBbBbBbBbBbBbBbBb"
+	if _, err := service.referral.Bind(referee, referrer); err != nil {
+		t.Fatalf("Failed to bind referrer: %v", err)
+	}
+
+	// trade points are the same as value
+	trade := func(ts int64, user string, value string) sync.TradeEvent {
+		return sync.TradeEvent{
+			PoolEvent: sync.PoolEvent{
+				Timestamp: ts,
+				User:      user,
+				Pool:      blockchain.PoolInfo{PairInfo: blockchain.PairInfo{Address: testPool}},
+			},
+			Value0: decimal.RequireFromString(value),
+			Value1: decimal.RequireFromString(value),
+		}
+	}
+
+	// referrer also trades in the same batch, and addresses are in different case between snapshots, where the
+	// referee earns 1.5 points in each snapshot, which is rounded down and carried over as residual
+	for i, v := range []struct{ referee, referrer string }{
+		{referee, "0x" + strings.ToUpper(referrer[2:])},
+		{strings.ToLower(referee), referrer},
+	} {
+		ts := int64(3600 * (i + 1))
+		info := sync.TimeInfo{Timestamp: ts, MinBlockNumber: uint64(ts) * 10, MaxBlockNumber: uint64(ts)*10 + 9}
+
+		batch := sync.BatchEvent{
+			TimeInfo:  info,
+			Snapshots: []sync.TimeInfo{info},
+			Trades:    []sync.TradeEvent{trade(ts, v.referee, "1.5"), trade(ts, v.referrer, "20")},
+		}
+
+		if err := service.OnEventBatch(batch); err != nil {
+			t.Fatalf("Failed to handle batch: %v", err)
+		}
+	}
+
+	var users []model.User
+	if err := service.store.DB.Where("address IN ?", []string{strings.ToLower(referee), strings.ToLower(referrer)}).
+		Order("address ASC").Find(&users).Error; err != nil {
+		t.Fatalf("Failed to get users: %v", err)
+	}
+
+	var total int64
+	if err := service.store.DB.Model(&model.User{}).Count(&total).Error; err != nil {
+		t.Fatalf("Failed to count users: %v", err)
+	}
+
+	if len(users) != 2 || total != 2 {
+		t.Fatalf("Users not deduplicated in different case, users = %+v, total = %v", users, total)
+	}
+
+	expected := []struct {
+		name     string
+		actual   decimal.Decimal
+		expected string
+	}{
+		{"referee trade points", users[0].TradePoints, "3"},
+		{"referrer trade points", users[1].TradePoints, "40"},
+		{"referrer referral points", users[1].ReferralPoints.Round(9), "0.3"},
+	}
+
+	for _, v := range expected {
+		if !v.actual.Equal(decimal.RequireFromString(v.expected)) {
+			t.Errorf("Unexpected %v, expected = %v, actual = %v", v.name, v.expected, v.actual)
+		}
+	}
+}
+
+func TestStatServiceReplaySkipped(t *testing.T) {
+	service := newTestStatService(t)
+
+	// events of pool skipped in the first snapshot, e.g. quarantined
+	batch := newTestBatch(3600, 7200)
+	skipped := sync.SkippedPool{TimeInfo: batch.Snapshots[0], Pool: testPool, Trades: 1, Reason: "quarantined"}
+	batch.Trades = batch.Trades[1:]
+	batch.Skipped = []sync.SkippedPool{skipped}
+
+	// skipped pools recorded only once along with applied snapshots
+	for i := 0; i < 2; i++ {
+		if err := service.OnEventBatch(batch); err != nil {
+			t.Fatalf("Failed to handle batch: %v", err)
+		}
+	}
+
+	pending, err := service.skipped.ListPending(10)
+	if err != nil || len(pending) != 1 || pending[0] != skipped {
+		t.Fatalf("Unexpected pending skipped snapshots %+v, err = %v", pending, err)
+	}
+
+	// TVL of existing pool is not updated by replay of older snapshot
+	service.tvl = func(bn uint64, pools []common.Address) ([][2]decimal.Decimal, error) {
+		result := make([][2]decimal.Decimal, len(pools))
+		for i := range result {
+			result[i] = [2]decimal.Decimal{decimal.NewFromInt(5), decimal.NewFromInt(5)}
+		}
+
+		return result, nil
+	}
+
+	replay := newTestBatch(3600)
+	replay.Snapshots = nil
+	if err := service.OnReplayBatch(pending[0], replay); err != nil {
+		t.Fatalf("Failed to replay skipped snapshot: %v", err)
+	}
+
+	if err := service.OnReplayBatch(pending[0], replay); !errors.Is(err, sync.ErrReplayed) {
+		t.Fatalf("Skipped snapshot replayed twice, err = %v", err)
+	}
+
+	assertApplied(t, service, 2)
+
+	var pool model.Pool
+	if err := service.store.DB.Where("address = ?", testPool.String()).Take(&pool).Error; err != nil {
+		t.Fatalf("Failed to get pool: %v", err)
+	}
+
+	if !pool.Tvl.IsZero() {
+		t.Fatalf("Pool TVL updated by replay, tvl = %v", pool.Tvl)
+	}
+
+	if pending, err = service.skipped.ListPending(10); err != nil || len(pending) != 0 {
+		t.Fatalf("Unexpected pending skipped snapshots %+v, err = %v", pending, err)
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/v3-Swampy/points-service/migration"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestStore creates a SQLite store with the latest schema in a temporary directory.
+func newTestStore(t *testing.T) *store.Store {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	if _, err = migration.NewDefaultMigrator(db).Up(0); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	s := store.NewStore(db)
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
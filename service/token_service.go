@@ -0,0 +1,286 @@
+package service
+
+import (
+	"encoding/json"
+	"net/url"
+	"slices"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/Conflux-Chain/go-conflux-util/api"
+	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/v3-Swampy/points-service/model"
+	"github.com/v3-Swampy/points-service/sync"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Maximum length of token overrides in database.
+const (
+	maxTokenLogoURLLength       = 512
+	maxTokenDisplaySymbolLength = 128
+)
+
+// ValidateTokenOverrides validates the logo URL and display symbol to override, where empty value is allowed.
+func ValidateTokenOverrides(logoURL, displaySymbol string) error {
+	if len(logoURL) > maxTokenLogoURLLength {
+		return errors.Errorf("Logo URL exceeds the maximum length %v", maxTokenLogoURLLength)
+	}
+
+	if len(logoURL) > 0 {
+		if u, err := url.Parse(logoURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return errors.Errorf("Invalid logo URL %v, which should be an absolute http or https URL", logoURL)
+		}
+	}
+
+	if len(displaySymbol) > maxTokenDisplaySymbolLength {
+		return errors.Errorf("Display symbol exceeds the maximum length %v", maxTokenDisplaySymbolLength)
+	}
+
+	if !utf8.ValidString(displaySymbol) {
+		return errors.New("Display symbol should be valid UTF-8 string")
+	}
+
+	return nil
+}
+
+type TokenService struct {
+	store *store.Store
+}
+
+func NewTokenService(store *store.Store) *TokenService {
+	return &TokenService{
+		store: store,
+	}
+}
+
+// BatchUpsert upserts the token metadata of given pools, and keeps the manual overrides of existing tokens.
+func (service *TokenService) BatchUpsert(pools []*model.Pool, dbTx ...*gorm.DB) error {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	tokens := make(map[string]*model.Token)
+	for _, v := range pools {
+		tokens[v.Token0] = &model.Token{
+			Address:  v.Token0,
+			Name:     v.Token0Name,
+			Symbol:   v.Token0Symbol,
+			Decimals: v.Token0Decimals,
+			Model:    model.Model{CreatedAt: v.UpdatedAt, UpdatedAt: v.UpdatedAt},
+		}
+
+		tokens[v.Token1] = &model.Token{
+			Address:  v.Token1,
+			Name:     v.Token1Name,
+			Symbol:   v.Token1Symbol,
+			Decimals: v.Token1Decimals,
+			Model:    model.Model{CreatedAt: v.UpdatedAt, UpdatedAt: v.UpdatedAt},
+		}
+	}
+
+	// deduplicated and sorted, since one statement could not upsert the same row twice
+	beans := make([]*model.Token, 0, len(tokens))
+	for _, v := range tokens {
+		beans = append(beans, v)
+	}
+
+	sort.Slice(beans, func(i, j int) bool {
+		return beans[i].Address < beans[j].Address
+	})
+
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "symbol", "decimals", "updated_at"}),
+	}).CreateInBatches(beans, upsertBatchSize).Error
+}
+
+// Get returns the token by address, or nil if not found.
+func (service *TokenService) Get(address string, dbTx ...*gorm.DB) (*model.Token, error) {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	var token model.Token
+	found, err := store.NewStore(db).Get(&token, "address = ?", address)
+	if err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get token by address")
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return &token, nil
+}
+
+// SetOverrides overrides the logo URL and display symbol of token, where empty value clears the override.
+func (service *TokenService) SetOverrides(address, logoURL, displaySymbol string, dbTx ...*gorm.DB) error {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	result := db.Model(&model.Token{}).Where("address = ?", address).Updates(map[string]any{
+		"logo_url":       logoURL,
+		"display_symbol": displaySymbol,
+	})
+	if result.Error != nil {
+		return api.ErrDatabaseCause(result.Error, "Failed to update token overrides")
+	}
+
+	if result.RowsAffected == 0 {
+		return api.ErrValidationStr("Failed to find token by address")
+	}
+
+	return nil
+}
+
+// List returns all tokens of tracked pools with the latest price and total TVL, which are in descending order of TVL.
+func (service *TokenService) List() ([]model.TokenInfo, error) {
+	var tokens []*model.Token
+	if err := service.store.DB.Order("address ASC").Find(&tokens).Error; err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get tokens")
+	}
+
+	tvls, err := service.sumTvls()
+	if err != nil {
+		return nil, err
+	}
+
+	prices, err := service.latestPrices()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.TokenInfo, 0, len(tokens))
+	for _, v := range tokens {
+		info := model.TokenInfo{
+			Address:       v.Address,
+			Name:          v.Name,
+			Symbol:        v.Symbol,
+			Decimals:      v.Decimals,
+			LogoURL:       v.LogoURL,
+			DisplaySymbol: v.DisplaySymbol,
+			Tvl:           tvls[v.Address],
+		}
+
+		if price, ok := prices[v.Address]; ok {
+			info.Price = price.Price
+			info.PriceTime = price.Timestamp
+		}
+
+		result = append(result, info)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Tvl.GreaterThan(result[j].Tvl)
+	})
+
+	return result, nil
+}
+
+// sumTvls returns the total TVL of each token in all tracked pools.
+func (service *TokenService) sumTvls() (map[string]decimal.Decimal, error) {
+	var pools []*model.Pool
+	if err := service.store.DB.Select("token0", "token1", "tvl0", "tvl1").Find(&pools).Error; err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get TVL of pools")
+	}
+
+	tvls := make(map[string]decimal.Decimal)
+	for _, v := range pools {
+		tvls[v.Token0] = tvls[v.Token0].Add(v.Tvl0)
+		tvls[v.Token1] = tvls[v.Token1].Add(v.Tvl1)
+	}
+
+	return tvls, nil
+}
+
+// latestPrices returns the latest price of each token.
+func (service *TokenService) latestPrices() (map[string]*model.TokenPrice, error) {
+	latest := service.store.DB.Model(&model.TokenPrice{}).Select("token, MAX(timestamp) AS timestamp").Group("token")
+
+	var prices []*model.TokenPrice
+	if err := service.store.DB.Model(&model.TokenPrice{}).
+		Select("token_prices.token, token_prices.timestamp, token_prices.price").
+		Joins("INNER JOIN (?) AS latest ON token_prices.token = latest.token AND token_prices.timestamp = latest.timestamp", latest).
+		Find(&prices).Error; err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get latest token prices")
+	}
+
+	result := make(map[string]*model.TokenPrice, len(prices))
+	for _, v := range prices {
+		result[v.Token] = v
+	}
+
+	return result, nil
+}
+
+// BatchInsertPrices inserts the token prices of snapshots, and ignores the prices that already exist.
+func (service *TokenService) BatchInsertPrices(prices []sync.TokenPrice, dbTx ...*gorm.DB) error {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
+	beans := make([]*model.TokenPrice, 0, len(prices))
+	for _, v := range prices {
+		route := make([]string, 0, len(v.Route))
+		for _, token := range v.Route {
+			route = append(route, token.String())
+		}
+
+		encoded, err := json.Marshal(route)
+		if err != nil {
+			return errors.WithMessage(err, "Failed to marshal price route")
+		}
+
+		beans = append(beans, &model.TokenPrice{
+			Token:     v.Token.String(),
+			Timestamp: v.Timestamp,
+			Price:     v.Price,
+			Source:    v.Source,
+			Route:     string(encoded),
+			Samples:   v.Samples,
+		})
+	}
+
+	return db.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(beans, upsertBatchSize).Error
+}
+
+// ListPrices returns the latest token prices in time range, which are in ascending order of timestamp.
+func (service *TokenService) ListPrices(token string, request model.HistoryRequest) ([]model.TokenPriceInfo, error) {
+	db := service.store.DB.Model(&model.TokenPrice{}).Where("token = ? AND timestamp >= ?", token, request.From)
+	if request.To > 0 {
+		db = db.Where("timestamp <= ?", request.To)
+	}
+
+	var prices []*model.TokenPrice
+	if err := db.Order("timestamp DESC").Limit(request.Limit).Find(&prices).Error; err != nil {
+		return nil, api.ErrDatabaseCause(err, "Failed to get token prices")
+	}
+
+	slices.Reverse(prices)
+
+	result := make([]model.TokenPriceInfo, 0, len(prices))
+	for _, v := range prices {
+		info := model.TokenPriceInfo{
+			Timestamp: v.Timestamp,
+			Price:     v.Price,
+			Source:    v.Source,
+			Samples:   v.Samples,
+		}
+
+		if err := json.Unmarshal([]byte(v.Route), &info.Route); err != nil {
+			return nil, api.ErrInternal(errors.WithMessage(err, "Failed to unmarshal price route"))
+		}
+
+		result = append(result, info)
+	}
+
+	return result, nil
+}
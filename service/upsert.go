@@ -0,0 +1,33 @@
+package service
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// upsertBatchSize is the maximum number of rows upserted in a single statement, so as not to exceed the limit of
+// bound parameters of database, e.g. SQLite and PostgreSQL.
+const upsertBatchSize = 500
+
+// excludedColumn returns the expression that refers to the value proposed for insertion in upsert statement,
+// i.e. VALUES(column) for MySQL and excluded.column for SQLite and PostgreSQL.
+func excludedColumn(db *gorm.DB, column string) string {
+	if db.Dialector.Name() == "mysql" {
+		return fmt.Sprintf("VALUES(%v)", column)
+	}
+
+	return fmt.Sprintf("excluded.%v", column)
+}
+
+// accumulateColumns returns the upsert assignments that add the proposed values onto the existing values of
+// specified columns in table.
+func accumulateColumns(db *gorm.DB, table string, columns ...string) clause.Set {
+	values := make(map[string]any, len(columns))
+	for _, v := range columns {
+		values[v] = gorm.Expr(fmt.Sprintf("%v.%v + %v", table, v, excludedColumn(db, v)))
+	}
+
+	return clause.Assignments(values)
+}
@@ -3,24 +3,89 @@ package service
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Conflux-Chain/go-conflux-util/api"
 	"github.com/Conflux-Chain/go-conflux-util/store"
+	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"github.com/v3-Swampy/points-service/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// maxPointsDecimals is the maximum decimals of points supported by database columns.
+const maxPointsDecimals = 18
+
+// PrecisionConfig is the decimal precision and rounding mode of user points, where the sub-unit remainder of
+// each user is carried to the next batch rather than lost.
+type PrecisionConfig struct {
+	Trade     int32  `default:"0"`     // decimals of trade points
+	Liquidity int32  `default:"1"`     // decimals of liquidity points
+	Rounding  string `default:"floor"` // floor, ceil, round (half away from zero) or bank (half to even)
+
+	Referral         int32  `default:"1"`     // decimals of referral points
+	ReferralRounding string `default:"floor"` // rounding mode of referral points
+}
+
+func (config PrecisionConfig) Validate() error {
+	for _, v := range []int32{config.Trade, config.Liquidity, config.Referral} {
+		if v < 0 || v > maxPointsDecimals {
+			return errors.Errorf("Decimals should be in range [0, %v]", maxPointsDecimals)
+		}
+	}
+
+	for _, v := range []string{config.Rounding, config.ReferralRounding} {
+		switch v {
+		case "floor", "ceil", "round", "bank":
+		default:
+			return errors.Errorf("Invalid rounding mode %v", v)
+		}
+	}
+
+	return nil
+}
+
+// round rounds the value to given decimals with the configured rounding mode.
+func (config PrecisionConfig) round(value decimal.Decimal, decimals int32) decimal.Decimal {
+	return roundWithMode(value, decimals, config.Rounding)
+}
+
+// roundReferral rounds the referral points with the configured precision of referral.
+func (config PrecisionConfig) roundReferral(value decimal.Decimal) decimal.Decimal {
+	return roundWithMode(value, config.Referral, config.ReferralRounding)
+}
+
+func roundWithMode(value decimal.Decimal, decimals int32, mode string) decimal.Decimal {
+	switch mode {
+	case "ceil":
+		return value.RoundCeil(decimals)
+	case "round":
+		return value.Round(decimals)
+	case "bank":
+		return value.RoundBank(decimals)
+	default:
+		return value.RoundFloor(decimals)
+	}
+}
+
 type UserService struct {
-	store *store.Store
+	store     *store.Store
+	precision PrecisionConfig
 }
 
-func NewUserService(store *store.Store) *UserService {
+func NewUserService(store *store.Store, precision PrecisionConfig) *UserService {
 	return &UserService{
-		store: store,
+		store:     store,
+		precision: precision,
 	}
 }
 
+// Precision returns the decimal precision of user points.
+func (service *UserService) Precision() PrecisionConfig {
+	return service.precision
+}
+
 func (service *UserService) Get(address string) (*model.User, error) {
 	var user model.User
 	found, err := service.store.Get(&user, "address = ?", address)
@@ -35,23 +100,33 @@ func (service *UserService) Get(address string) (*model.User, error) {
 	return &user, nil
 }
 
-func (service *UserService) Add(address string, tradePoints decimal.Decimal, liquidityPoints decimal.Decimal) (uint64, error) {
+func (service *UserService) Add(address string, tradePoints decimal.Decimal, liquidityPoints decimal.Decimal, dbTx ...*gorm.DB) (uint64, error) {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
 	user := &model.User{
 		Address:         address,
 		TradePoints:     tradePoints,
 		LiquidityPoints: liquidityPoints,
 	}
 
-	if err := service.store.DB.Create(user).Error; err != nil {
+	if err := db.Create(user).Error; err != nil {
 		return 0, api.ErrDatabaseCause(err, "Failed to create user")
 	}
 
 	return user.ID, nil
 }
 
-func (service *UserService) DeltaUpdate(address string, tradePoints decimal.Decimal, liquidityPoints decimal.Decimal) error {
+func (service *UserService) DeltaUpdate(address string, tradePoints decimal.Decimal, liquidityPoints decimal.Decimal, dbTx ...*gorm.DB) error {
+	db := service.store.DB
+	if len(dbTx) > 0 {
+		db = dbTx[0]
+	}
+
 	var user model.User
-	found, err := service.store.Get(&user, "address = ?", address)
+	found, err := store.NewStore(db).Get(&user, "address = ?", address)
 
 	if err != nil {
 		return api.ErrDatabaseCause(err, "Failed to get user by address")
@@ -61,16 +136,14 @@ func (service *UserService) DeltaUpdate(address string, tradePoints decimal.Deci
 		return api.ErrValidationStr("Failed to find user by address")
 	}
 
-	result := service.store.DB.Exec(`
-            UPDATE users 
-            SET 
-                trade_points = trade_points + ?,
-                liquidity_points = liquidity_points + ?,
-                updated_at = NOW()
-            WHERE address = ? 
-            AND (trade_points + ?) >= 0 
-            AND (liquidity_points + ?) >= 0
-        `, tradePoints, liquidityPoints, address, tradePoints, liquidityPoints)
+	result := db.Model(&model.User{}).
+		Where("address = ?", address).
+		Where("trade_points + ? >= 0 AND liquidity_points + ? >= 0", tradePoints, liquidityPoints).
+		Updates(map[string]any{
+			"trade_points":     gorm.Expr("trade_points + ?", tradePoints),
+			"liquidity_points": gorm.Expr("liquidity_points + ?", liquidityPoints),
+			"updated_at":       time.Now(),
+		})
 
 	if result.Error != nil {
 		return result.Error
@@ -89,35 +162,72 @@ func (service *UserService) BatchDeltaUpsert(users []*model.User, dbTx ...*gorm.
 		db = dbTx[0]
 	}
 
-	var placeholders string
-	var params []interface{}
-	size := len(users)
-	for i, u := range users {
-		placeholders += "(?,?,?,?,?)"
-		if i != size-1 {
-			placeholders += ",\n\t\t\t"
+	if err := service.applyPrecision(users, db); err != nil {
+		return err
+	}
+
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "address"}},
+		DoUpdates: append(
+			accumulateColumns(db, "users", "trade_points", "liquidity_points", "referral_points"),
+			clause.AssignmentColumns([]string{"trade_residual", "liquidity_residual", "referral_residual", "updated_at"})...,
+		),
+	}).CreateInBatches(users, upsertBatchSize).Error
+}
+
+// applyPrecision rounds the delta points of users to the configured precision, taking the residuals of existing
+// users into account, and sets the new residuals to be stored along with points. Note, user addresses should be
+// normalized in lowercase, so that residuals are matched on case sensitive databases, e.g. SQLite and PostgreSQL.
+func (service *UserService) applyPrecision(users []*model.User, db *gorm.DB) error {
+	residuals := make(map[string]*model.User, len(users))
+
+	for start := 0; start < len(users); start += upsertBatchSize {
+		end := min(start+upsertBatchSize, len(users))
+
+		addresses := make([]string, 0, end-start)
+		for _, u := range users[start:end] {
+			addresses = append(addresses, u.Address)
+		}
+
+		var existing []*model.User
+		if err := db.Select("address", "trade_residual", "liquidity_residual", "referral_residual").
+			Where("address IN ?", addresses).
+			Find(&existing).Error; err != nil {
+			return errors.WithMessage(err, "Failed to get residuals of users")
+		}
+
+		for _, u := range existing {
+			residuals[u.Address] = u
+		}
+	}
+
+	for _, u := range users {
+		trade, liquidity, referral := u.TradePoints, u.LiquidityPoints, u.ReferralPoints
+		if r, ok := residuals[u.Address]; ok {
+			trade = trade.Add(r.TradeResidual)
+			liquidity = liquidity.Add(r.LiquidityResidual)
+			referral = referral.Add(r.ReferralResidual)
 		}
-		params = append(params, []interface{}{u.Address, u.TradePoints, u.LiquidityPoints, u.CreatedAt, u.UpdatedAt}...)
-	}
-
-	sqlString := fmt.Sprintf(`
-		insert into 
-    		users(address, trade_points, liquidity_points, created_at, updated_at)
-		values
-			%s
-		on duplicate key update
-			address = values(address),
-			trade_points = trade_points + values(trade_points),
-			liquidity_points = liquidity_points + values(liquidity_points),
-			updated_at = values(updated_at)                 
-	`, placeholders)
-
-	return db.Exec(sqlString, params...).Error
+
+		u.TradePoints = service.precision.round(trade, service.precision.Trade)
+		u.TradeResidual = trade.Sub(u.TradePoints)
+		u.LiquidityPoints = service.precision.round(liquidity, service.precision.Liquidity)
+		u.LiquidityResidual = liquidity.Sub(u.LiquidityPoints)
+		u.ReferralPoints = service.precision.roundReferral(referral)
+		u.ReferralResidual = referral.Sub(u.ReferralPoints)
+	}
+
+	return nil
 }
 
 func (service *UserService) List(request model.UserPagingRequest) (total int64, users []*model.User, err error) {
 	db := service.store.DB.Model(&model.User{})
 
+	// referral leaderboard only ranks users who earned referral points
+	if strings.EqualFold(request.SortField, "referral") {
+		db = db.Where("referral_points > 0")
+	}
+
 	if err = db.Count(&total).Error; err != nil {
 		return 0, nil, api.ErrDatabaseCause(err, "Failed to get count of users")
 	}
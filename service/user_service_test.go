@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mcuadros/go-defaults"
+	"github.com/shopspring/decimal"
+	"github.com/v3-Swampy/points-service/model"
+)
+
+func TestUserServiceReferralResidual(t *testing.T) {
+	var precision PrecisionConfig
+	defaults.SetDefaults(&precision)
+
+	service := NewUserService(newTestStore(t), precision)
+
+	// sub-unit bonus of referral points, which would be lost without residual
+	bonus := decimal.RequireFromString("0.05")
+	for i := 0; i < 100; i++ {
+		u := model.NewUser(testReferrer, decimal.Zero, decimal.Zero, time.Unix(int64(i), 0))
+		u.ReferralPoints = bonus
+
+		if err := service.BatchDeltaUpsert([]*model.User{u}); err != nil {
+			t.Fatalf("Failed to upsert user: %v", err)
+		}
+	}
+
+	var user model.User
+	if err := service.store.DB.Where("address = ?", testReferrer).First(&user).Error; err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+
+	// SQLite accumulates decimals in floating point
+	if !user.ReferralPoints.Round(9).Equal(decimal.NewFromInt(5)) {
+		t.Fatalf("Expected referral points 5, got %v", user.ReferralPoints)
+	}
+
+	if !user.ReferralResidual.IsZero() {
+		t.Fatalf("Expected no referral residual, got %v", user.ReferralResidual)
+	}
+}
@@ -1,6 +1,9 @@
 package sync
 
 import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/shopspring/decimal"
 	"github.com/v3-Swampy/points-service/blockchain"
 )
@@ -9,6 +12,9 @@ type PoolEvent struct {
 	Timestamp int64  // unix timestamp in seconds
 	User      string // user address, e.g. trader or liquidity provider
 	Pool      blockchain.PoolInfo
+
+	Price0 decimal.Decimal // token0 price in USD used to evaluate values
+	Price1 decimal.Decimal // token1 price in USD used to evaluate values
 }
 
 type TradeEvent struct {
@@ -25,6 +31,22 @@ type LiquidityEvent struct {
 	Value1Seconds decimal.Decimal // liquidity1 * price * seconds
 }
 
+// Token price sources.
+const (
+	PriceSourceSwappi = "swappi"
+	PriceSourceVswap  = "vswap"
+)
+
+// TokenPrice is the token price in USD used to evaluate values of a snapshot.
+type TokenPrice struct {
+	Token     common.Address
+	Timestamp int64            // unix timestamp in seconds
+	Price     decimal.Decimal  // average price of all samples
+	Source    string           // price source of the latest sample, e.g. swappi or vswap
+	Route     []common.Address // token route of the latest sample, e.g. [token, WCFX, USDT]
+	Samples   int              // number of sampled blocks
+}
+
 type TimeInfo struct {
 	Timestamp int64
 
@@ -32,19 +54,43 @@ type TimeInfo struct {
 	MaxBlockNumber uint64
 }
 
+// SkippedPool is a pool whose events of snapshot are skipped, e.g. pool quarantined, which could be replayed later.
+type SkippedPool struct {
+	TimeInfo
+
+	Pool        common.Address
+	Trades      int    // number of skipped trade records
+	Liquidities int    // number of skipped liquidity records
+	Reason      string // error that caused to skip
+}
+
 type BatchEvent struct {
 	TimeInfo
 
+	Snapshots   []TimeInfo // time info of all merged snapshots in order
 	Trades      []TradeEvent
 	Liquidities []LiquidityEvent
+	Prices      []TokenPrice
+	Skipped     []SkippedPool // pools skipped in snapshots, which should be recorded along with applied snapshots
 }
 
 func (event *BatchEvent) Merge(other BatchEvent) {
 	event.TimeInfo = other.TimeInfo
+	event.Snapshots = append(event.Snapshots, other.Snapshots...)
 	event.Trades = append(event.Trades, other.Trades...)
 	event.Liquidities = append(event.Liquidities, other.Liquidities...)
+	event.Prices = append(event.Prices, other.Prices...)
+	event.Skipped = append(event.Skipped, other.Skipped...)
 }
 
 type EventHandler interface {
 	OnEventBatch(event BatchEvent) error
 }
+
+// ErrReplayed is returned by ReplayHandler if skipped snapshot of pool already replayed.
+var ErrReplayed = errors.New("Skipped snapshot already replayed")
+
+// ReplayHandler applies events of pool that skipped in an applied snapshot, e.g. pool quarantined.
+type ReplayHandler interface {
+	OnReplayBatch(skipped SkippedPool, event BatchEvent) error
+}
@@ -0,0 +1,220 @@
+package parsing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/alert"
+	"github.com/Conflux-Chain/go-conflux-util/health"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+type AlertOption struct {
+	// channels configured in go-conflux-util alert, defaults to all channels
+	Channels []string
+	// alert if stage keeps retrying longer than this
+	RetryThreshold time.Duration `default:"10m"`
+	// alert if lag of contract parser snapshots exceeds this
+	LagThreshold time.Duration `default:"3h"`
+	// remind if unrecovered, while duplicate alerts in between are suppressed
+	Remind      time.Duration `default:"1h"`
+	SendTimeout time.Duration `default:"10s"`
+}
+
+// stageAlert sends alerts to channels when sync pipeline stalls, and notifies once recovered. Alerts are disabled
+// unless initialized with any channel.
+type stageAlert struct {
+	mu       sync.Mutex
+	option   AlertOption
+	channels []alert.Channel
+	retries  map[string]*health.TimedCounter // stage => retry counter
+	lag      *health.TimedCounter
+	pools    map[common.Address]*health.TimedCounter // quarantined pool => counter
+}
+
+var pipelineAlert stageAlert
+
+// InitAlert initializes the alert of sync pipeline stalls with channels configured in go-conflux-util.
+func InitAlert(option ...AlertOption) error {
+	opt := optionWithDefault(option...)
+
+	var channels []alert.Channel
+	if len(opt.Channels) == 0 {
+		channels = alert.DefaultManager().All("")
+	} else {
+		for _, name := range opt.Channels {
+			ch, ok := alert.DefaultManager().Channel(name)
+			if !ok {
+				return alert.ErrChannelNotFound(name)
+			}
+
+			channels = append(channels, ch)
+		}
+	}
+
+	pipelineAlert.mu.Lock()
+	defer pipelineAlert.mu.Unlock()
+
+	pipelineAlert.option = opt
+	pipelineAlert.channels = channels
+	pipelineAlert.retries = make(map[string]*health.TimedCounter)
+	pipelineAlert.lag = health.NewTimedCounter(health.TimedCounterConfig{
+		Remind: opt.Remind,
+	})
+	pipelineAlert.pools = make(map[common.Address]*health.TimedCounter)
+
+	logrus.WithField("channels", len(channels)).Debug("Sync pipeline alert initialized")
+
+	return nil
+}
+
+// OnRetry updates the retry status of stage, and alerts if retrying for a long time.
+func (sa *stageAlert) OnRetry(stage string, err error) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	if len(sa.channels) == 0 {
+		return
+	}
+
+	unhealthy, unrecovered, elapsed := sa.retryCounter(stage).OnFailure()
+	if unhealthy || unrecovered {
+		sa.send(alert.SeverityHigh, fmt.Sprintf("Sync %v stalled", stage),
+			fmt.Sprintf("Sync %v keeps retrying for %v, error = %v", stage, elapsed.Truncate(time.Second), err))
+	}
+}
+
+// OnSuccess updates the retry status of stage, and notifies if recovered from alert.
+func (sa *stageAlert) OnSuccess(stage string) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	if len(sa.channels) == 0 {
+		return
+	}
+
+	if recovered, elapsed := sa.retryCounter(stage).OnSuccess(); recovered {
+		sa.send(alert.SeverityLow, fmt.Sprintf("Sync %v recovered", stage),
+			fmt.Sprintf("Sync %v recovered after retrying for %v", stage, elapsed.Truncate(time.Second)))
+	}
+}
+
+// OnLag updates the lag of applied snapshots behind contract parser, and alerts if lag exceeds threshold.
+func (sa *stageAlert) OnLag(lag time.Duration) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	if len(sa.channels) == 0 {
+		return
+	}
+
+	if lag <= sa.option.LagThreshold {
+		if recovered, elapsed := sa.lag.OnSuccess(); recovered {
+			sa.send(alert.SeverityLow, "Sync lag recovered",
+				fmt.Sprintf("Sync lag recovered to %v after %v", lag, elapsed.Truncate(time.Second)))
+		}
+
+		return
+	}
+
+	if unhealthy, unrecovered, _ := sa.lag.OnFailure(); unhealthy || unrecovered {
+		sa.send(alert.SeverityHigh, "Sync lag too large",
+			fmt.Sprintf("Sync lags behind contract parser for %v, threshold = %v", lag, sa.option.LagThreshold))
+	}
+}
+
+// OnDisagreement alerts if contract parser endpoints return different data of the same snapshot.
+func (sa *stageAlert) OnDisagreement(content string) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	if len(sa.channels) == 0 {
+		logrus.Warn(content)
+		return
+	}
+
+	sa.send(alert.SeverityHigh, "Contract parser endpoints disagree", content)
+}
+
+// OnQuarantine alerts if pool quarantined or events of quarantined pool skipped, and notifies once pool released
+// with nil error.
+func (sa *stageAlert) OnQuarantine(pool common.Address, err error) {
+	sa.mu.Lock()
+	defer sa.mu.Unlock()
+
+	if len(sa.channels) == 0 {
+		return
+	}
+
+	counter, ok := sa.pools[pool]
+	if !ok {
+		if err == nil {
+			return
+		}
+
+		// alert immediately once quarantined
+		counter = health.NewTimedCounter(health.TimedCounterConfig{Remind: sa.option.Remind})
+		sa.pools[pool] = counter
+	}
+
+	if err == nil {
+		if recovered, elapsed := counter.OnSuccess(); recovered {
+			sa.send(alert.SeverityLow, "Pool quarantine released",
+				fmt.Sprintf("Pool %v released from quarantine after %v", pool, elapsed.Truncate(time.Second)))
+		}
+
+		delete(sa.pools, pool)
+
+		return
+	}
+
+	if unhealthy, unrecovered, elapsed := counter.OnFailure(); unhealthy || unrecovered {
+		sa.send(alert.SeverityHigh, "Pool quarantined",
+			fmt.Sprintf("Pool %v quarantined for %v and its events are skipped, error = %v",
+				pool, elapsed.Truncate(time.Second), err))
+	}
+}
+
+func (sa *stageAlert) retryCounter(stage string) *health.TimedCounter {
+	counter, ok := sa.retries[stage]
+	if !ok {
+		counter = health.NewTimedCounter(health.TimedCounterConfig{
+			Threshold: sa.option.RetryThreshold,
+			Remind:    sa.option.Remind,
+		})
+		sa.retries[stage] = counter
+	}
+
+	return counter
+}
+
+// send notifies all channels asynchronously, so as not to block the sync pipeline.
+func (sa *stageAlert) send(severity alert.Severity, title, content string) {
+	note := &alert.Notification{
+		Title:    title,
+		Content:  content,
+		Severity: severity,
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"title":    title,
+		"severity": severity,
+	})
+	logger.Info(content)
+
+	channels, timeout := sa.channels, sa.option.SendTimeout
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		for _, ch := range channels {
+			if err := ch.Send(ctx, note); err != nil {
+				logger.WithError(err).WithField("channel", ch.Name()).Info("Failed to send alert")
+			}
+		}
+	}()
+}
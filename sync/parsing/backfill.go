@@ -0,0 +1,310 @@
+package parsing
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/v3-Swampy/points-service/sync"
+	"golang.org/x/sync/errgroup"
+)
+
+type BackfillOption struct {
+	Workers int `default:"8"` // number of snapshots to poll and emit concurrently
+
+	MaxRetries       int           `default:"10"` // maximum number of retries of a window of snapshots on failure
+	IntervalError    time.Duration `default:"5s"` // backoff before the first retry, which doubles for each retry
+	MaxIntervalError time.Duration `default:"1m"` // maximum backoff between retries
+}
+
+// Backfiller polls and emits historical snapshots concurrently, and applies them in order of timestamp, so as to
+// catch up quickly, e.g. on a fresh deployment.
+//
+// Note, applied snapshots are checkpointed by handler, so backfill could be resumed, and the poller of service
+// continues from the last applied snapshot.
+type Backfiller struct {
+	option  BackfillOption
+	poller  *Poller
+	emitter *Emitter
+	handler sync.EventHandler
+	logger  *logrus.Entry
+}
+
+func NewBackfiller(poller *Poller, emitter *Emitter, handler sync.EventHandler, option ...BackfillOption) *Backfiller {
+	opt := optionWithDefault(option...)
+	opt.Workers = max(opt.Workers, 1)
+
+	return &Backfiller{
+		option:  opt,
+		poller:  poller,
+		emitter: emitter,
+		handler: handler,
+		logger:  logrus.WithField("worker", "sync.backfiller"),
+	}
+}
+
+// Run backfills snapshots in range [from, to], and returns the number of applied snapshots.
+//
+// If from is 0 or already applied, backfill resumes from the next snapshot of poller. Otherwise, from should be
+// contiguous with the last applied snapshot, unless nothing applied yet. If to is 0 or not available yet, backfill
+// ends with the latest snapshot of contract parser.
+func (backfiller *Backfiller) Run(ctx context.Context, from, to int64) (int, error) {
+	poller := backfiller.poller
+
+	start, lastMaxBlockNumber := poller.nextTimestamp, poller.lastMaxBN
+	if from > start {
+		if poller.resumed {
+			return 0, errors.Errorf("Snapshots not applied before %v, which should be backfilled first", formatTs(from))
+		}
+
+		if (from-start)%poller.intervalSecs != 0 {
+			return 0, errors.Errorf("Timestamp %v should be multiple of snapshot interval %v secs", from, poller.intervalSecs)
+		}
+
+		start = from
+	}
+
+	latestTimestamp, err := poller.client.LatestTimestamp()
+	if err != nil {
+		return 0, errors.WithMessage(err, "Failed to poll latest timestamp")
+	}
+
+	if to == 0 || to > latestTimestamp {
+		to = latestTimestamp
+	}
+
+	backfiller.logger.WithFields(logrus.Fields{
+		"from":    formatTs(start),
+		"to":      formatTs(to),
+		"workers": backfiller.option.Workers,
+	}).Info("Backfill started")
+
+	var applied int
+
+	for timestamp := start; timestamp <= to; {
+		begin := time.Now()
+
+		var timestamps []int64
+		for ; timestamp <= to && len(timestamps) < backfiller.option.Workers; timestamp += poller.intervalSecs {
+			timestamps = append(timestamps, timestamp)
+		}
+
+		batch, err := backfiller.retryWindow(ctx, timestamps, lastMaxBlockNumber)
+		if err != nil {
+			return applied, err
+		}
+
+		applied += len(timestamps)
+		lastMaxBlockNumber = batch.MaxBlockNumber
+
+		backfiller.logger.WithFields(logrus.Fields{
+			"ts":        formatTs(batch.Timestamp),
+			"snapshots": len(timestamps),
+			"trade":     len(batch.Trades),
+			"liquidity": len(batch.Liquidities),
+			"elapsed":   time.Since(begin),
+		}).Info("Backfill move forward")
+	}
+
+	return applied, nil
+}
+
+// Replay replays events of pools skipped in applied snapshots, e.g. pool quarantined, and returns the number of
+// replayed snapshots of pools. Each skipped snapshot of pool is polled and emitted again in the recorded block
+// window, and replays are retried with backoff on failure. Skipped snapshots that already replayed are ignored.
+func (backfiller *Backfiller) Replay(ctx context.Context, skipped []sync.SkippedPool, handler sync.ReplayHandler) (int, error) {
+	var replayed int
+
+	for _, v := range skipped {
+		logger := backfiller.logger.WithFields(logrus.Fields{
+			"ts":   formatTs(v.Timestamp),
+			"pool": v.Pool,
+		})
+
+		var done bool
+		err := backfiller.retry(ctx, logger, func() error {
+			err := backfiller.replay(ctx, v, handler)
+			if errors.Is(err, sync.ErrReplayed) {
+				done = true
+				return nil
+			}
+
+			return err
+		})
+		if err != nil {
+			return replayed, errors.WithMessagef(err, "Failed to replay snapshot %v of pool %v", formatTs(v.Timestamp), v.Pool)
+		}
+
+		if done {
+			logger.Debug("Skipped snapshot of pool already replayed")
+			continue
+		}
+
+		logger.Info("Skipped snapshot of pool replayed")
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+// replay polls, emits and applies events of pool in a skipped snapshot.
+func (backfiller *Backfiller) replay(ctx context.Context, skipped sync.SkippedPool, handler sync.ReplayHandler) error {
+	data, ok, err := backfiller.poller.poll(skipped.Timestamp, 0)
+	if err != nil {
+		return errors.WithMessage(err, "Failed to poll snapshot")
+	}
+
+	if !ok {
+		return errors.New("Snapshot not available")
+	}
+
+	// only events of the skipped pool in the recorded block window
+	data.TimeInfo = skipped.TimeInfo
+	pools := data.Pools
+	data.Pools = nil
+	for _, v := range pools {
+		if v.Address == skipped.Pool {
+			data.Pools = append(data.Pools, v)
+		}
+	}
+
+	event, err := backfiller.emitter.emit(ctx, data)
+	if err != nil {
+		return errors.WithMessage(err, "Failed to emit snapshot")
+	}
+
+	return handler.OnReplayBatch(skipped, event)
+}
+
+// retryWindow backfills a window of snapshots, and retries with backoff on failure, e.g. RPC unavailable. Note,
+// applied snapshots are excluded by handler, so it is safe to retry even if failed after applied.
+func (backfiller *Backfiller) retryWindow(ctx context.Context, timestamps []int64, lastMaxBlockNumber uint64) (sync.BatchEvent, error) {
+	var batch sync.BatchEvent
+
+	err := backfiller.retry(ctx, backfiller.logger.WithField("ts", formatTs(timestamps[0])), func() (err error) {
+		batch, err = backfiller.fillWindow(ctx, timestamps, lastMaxBlockNumber)
+		return err
+	})
+
+	return batch, err
+}
+
+// retry calls fn until succeeded, and retries with exponential backoff on failure, up to MaxRetries times.
+func (backfiller *Backfiller) retry(ctx context.Context, logger *logrus.Entry, fn func() error) error {
+	backoff := backfiller.option.IntervalError
+
+	for retries := 0; ; retries++ {
+		err := fn()
+		if err == nil || ctx.Err() != nil || retries >= backfiller.option.MaxRetries {
+			return err
+		}
+
+		logger.WithError(err).WithFields(logrus.Fields{
+			"retries": retries,
+			"backoff": backoff,
+		}).Warn("Failed to backfill snapshots, retry later")
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, backfiller.option.MaxIntervalError)
+	}
+}
+
+// fillWindow polls, emits and applies snapshots of given timestamps, which are contiguous with the last snapshot.
+func (backfiller *Backfiller) fillWindow(ctx context.Context, timestamps []int64, lastMaxBlockNumber uint64) (sync.BatchEvent, error) {
+	snapshots, err := backfiller.poll(ctx, timestamps)
+	if err != nil {
+		return sync.BatchEvent{}, err
+	}
+
+	// ensure block windows are contiguous, so that no blocks skipped or double counted
+	for i := range snapshots {
+		if lastMaxBlockNumber > 0 {
+			snapshots[i].MinBlockNumber = lastMaxBlockNumber + 1
+		}
+
+		if snapshots[i].MinBlockNumber > snapshots[i].MaxBlockNumber {
+			return sync.BatchEvent{}, errors.Errorf("Invalid block window [%v, %v] of snapshot %v",
+				snapshots[i].MinBlockNumber, snapshots[i].MaxBlockNumber, formatTs(snapshots[i].Timestamp))
+		}
+
+		lastMaxBlockNumber = snapshots[i].MaxBlockNumber
+	}
+
+	batch, err := backfiller.emit(ctx, snapshots)
+	if err != nil {
+		return sync.BatchEvent{}, err
+	}
+
+	if err = backfiller.handler.OnEventBatch(batch); err != nil {
+		return sync.BatchEvent{}, errors.WithMessage(err, "Failed to handle events in batch")
+	}
+
+	return batch, nil
+}
+
+// poll polls snapshots of given timestamps concurrently, and returns in order of timestamps.
+func (backfiller *Backfiller) poll(ctx context.Context, timestamps []int64) ([]Snapshot, error) {
+	snapshots := make([]Snapshot, len(timestamps))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	for i, timestamp := range timestamps {
+		group.Go(func() error {
+			if err := groupCtx.Err(); err != nil {
+				return err
+			}
+
+			// min block number will be overridden to keep block windows contiguous
+			data, ok, err := backfiller.poller.poll(timestamp, 0)
+			if err != nil {
+				return errors.WithMessagef(err, "Failed to poll snapshot %v", formatTs(timestamp))
+			}
+
+			if !ok {
+				return errors.Errorf("Snapshot %v not available yet", formatTs(timestamp))
+			}
+
+			snapshots[i] = data
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// emit emits events of snapshots concurrently, and merges them in order of timestamps.
+func (backfiller *Backfiller) emit(ctx context.Context, snapshots []Snapshot) (sync.BatchEvent, error) {
+	events := make([]sync.BatchEvent, len(snapshots))
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	for i, data := range snapshots {
+		group.Go(func() (err error) {
+			if events[i], err = backfiller.emitter.emit(groupCtx, data); err != nil {
+				return errors.WithMessagef(err, "Failed to emit snapshot %v", formatTs(data.Timestamp))
+			}
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return sync.BatchEvent{}, err
+	}
+
+	var batch sync.BatchEvent
+	for _, v := range events {
+		batch.Merge(v)
+	}
+
+	return batch, nil
+}
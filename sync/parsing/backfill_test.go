@@ -0,0 +1,153 @@
+package parsing
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/v3-Swampy/points-service/sync"
+)
+
+// fakeResolver resolves block number as the timestamp plus 1.
+type fakeResolver struct{}
+
+func (fakeResolver) GetBlockNumberByTime(timestampSecs int64, after bool) (uint64, error) {
+	return uint64(timestampSecs) + 1, nil
+}
+
+// fakeHandler records the timestamps of handled snapshots and replayed pools.
+type fakeHandler struct {
+	timestamps []int64
+	replayed   []sync.SkippedPool
+}
+
+func (handler *fakeHandler) OnReplayBatch(skipped sync.SkippedPool, event sync.BatchEvent) error {
+	for _, v := range handler.replayed {
+		if v == skipped {
+			return sync.ErrReplayed
+		}
+	}
+
+	if event.TimeInfo != skipped.TimeInfo {
+		return errors.New("block window not replayed as recorded")
+	}
+
+	handler.replayed = append(handler.replayed, skipped)
+
+	return nil
+}
+
+func (handler *fakeHandler) OnEventBatch(event sync.BatchEvent) error {
+	for _, v := range event.Snapshots {
+		handler.timestamps = append(handler.timestamps, v.Timestamp)
+	}
+
+	return nil
+}
+
+// newTestBackfiller creates a backfiller against a fake contract parser synced to 10800, whose snapshot data fails
+// for the given number of requests at first.
+func newTestBackfiller(t *testing.T, failures int32, option BackfillOption) (*Backfiller, *fakeHandler) {
+	t.Helper()
+
+	var failed atomic.Int32
+	url := newFakeParser(t, func(method string, params []any) (any, error) {
+		switch method {
+		case "latestTimestamp":
+			return 10800, nil
+		case "getHourlyTradeData", "getHourlyLiquidityData":
+			if failed.Add(1) <= failures {
+				return nil, errors.New("temporary failure")
+			}
+
+			return map[string]any{"total": 0, "data": []any{}}, nil
+		default:
+			return nil, errors.New("method not found")
+		}
+	}).URL
+
+	client, err := NewFailoverClient([]string{url}, false, PagingOption{})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	poller := &Poller{
+		client:        client,
+		resolver:      fakeResolver{},
+		nextTimestamp: 7200,
+		intervalSecs:  3600,
+		pools:         []common.Address{common.HexToAddress("0x1")},
+		logger:        logrus.WithField("worker", "test.poller"),
+	}
+
+	handler := &fakeHandler{}
+
+	return NewBackfiller(poller, NewEmitter(nil), handler, option), handler
+}
+
+func TestBackfillRetryWindow(t *testing.T) {
+	backfiller, handler := newTestBackfiller(t, 3, BackfillOption{IntervalError: time.Millisecond})
+
+	applied, err := backfiller.Run(context.Background(), 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to backfill: %v", err)
+	}
+
+	if applied != 2 || len(handler.timestamps) != 2 {
+		t.Fatalf("Expected 2 snapshots applied, got %v, handled %v", applied, handler.timestamps)
+	}
+}
+
+func TestBackfillMaxRetries(t *testing.T) {
+	backfiller, handler := newTestBackfiller(t, 1000, BackfillOption{MaxRetries: 2, IntervalError: time.Millisecond})
+
+	if _, err := backfiller.Run(context.Background(), 0, 0); err == nil {
+		t.Fatal("Expected error once retries exhausted")
+	}
+
+	if len(handler.timestamps) != 0 {
+		t.Fatalf("Expected no snapshot handled, got %v", handler.timestamps)
+	}
+}
+
+func TestBackfillCancelRetry(t *testing.T) {
+	backfiller, _ := newTestBackfiller(t, 1000, BackfillOption{IntervalError: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := backfiller.Run(ctx, 0, 0); err == nil {
+		t.Fatal("Expected error once cancelled")
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Backoff not cancelled, elapsed %v", elapsed)
+	}
+}
+
+func TestBackfillReplay(t *testing.T) {
+	backfiller, handler := newTestBackfiller(t, 3, BackfillOption{IntervalError: time.Millisecond})
+
+	pool := common.HexToAddress("0x1")
+	skipped := []sync.SkippedPool{
+		{TimeInfo: sync.TimeInfo{Timestamp: 3600, MinBlockNumber: 1, MaxBlockNumber: 3601}, Pool: pool},
+		{TimeInfo: sync.TimeInfo{Timestamp: 7200, MinBlockNumber: 3602, MaxBlockNumber: 7201}, Pool: pool},
+	}
+
+	// retried on failure
+	replayed, err := backfiller.Replay(context.Background(), skipped, handler)
+	if err != nil || replayed != 2 || len(handler.replayed) != 2 {
+		t.Fatalf("Unexpected replayed %v, handled %+v, err = %v", replayed, handler.replayed, err)
+	}
+
+	// already replayed
+	if replayed, err = backfiller.Replay(context.Background(), skipped, handler); err != nil || replayed != 0 {
+		t.Fatalf("Unexpected replayed %v, err = %v", replayed, err)
+	}
+}
@@ -45,6 +45,7 @@ func (batcher *Batcher) Run(ctx context.Context, wg *stdSync.WaitGroup, eventCh
 			batch = batcher.mustHandle(ctx, batch)
 			ticker.Reset(batcher.option.BatchTimeout)
 		case event := <-eventCh:
+			pipelineMetrics.Buffer(StageEmitter, len(eventCh))
 			batch.Merge(event)
 
 			if len(batch.Trades)+len(batch.Liquidities) >= batcher.option.BatchSize {
@@ -66,6 +67,8 @@ func (batcher *Batcher) mustHandle(ctx context.Context, batch sync.BatchEvent) s
 
 		if err := batcher.handler.OnEventBatch(batch); err != nil {
 			logger.WithError(err).Warn("Failed to handle events in batch")
+			pipelineMetrics.Retry(StageBatcher)
+			pipelineHealth.OnFailure(StageBatcher, err)
 
 			select {
 			case <-ctx.Done():
@@ -79,6 +82,10 @@ func (batcher *Batcher) mustHandle(ctx context.Context, batch sync.BatchEvent) s
 				"trade":     len(batch.Trades),
 				"liquidity": len(batch.Liquidities),
 			}).Info("Batch events handled")
+			pipelineMetrics.Latency(StageBatcher, start)
+			pipelineHealth.OnSuccess(StageBatcher)
+			pipelineMetrics.BatchSize(len(batch.Trades), len(batch.Liquidities))
+			pipelineLag.OnApplied(batch.Timestamp)
 
 			return sync.BatchEvent{}
 		}
@@ -1,16 +1,25 @@
 package parsing
 
-import "github.com/mcuadros/go-defaults"
+import (
+	"slices"
+
+	"github.com/mcuadros/go-defaults"
+	"github.com/v3-Swampy/points-service/blockchain"
+)
 
 type Config struct {
 	Poller struct {
 		RpcUrl  string
+		RpcUrls []string // multiple contract parser endpoints for failover, along with RpcUrl if any
 		ScanUrl string
 		Option  PollOption
+
+		BlockResolver blockchain.BlockResolverConfig
 	}
 
 	Emitter EmitOption
 	Batcher BatchOption
+	Alert   AlertOption
 }
 
 func optionWithDefault[T any](option ...T) T {
@@ -24,3 +33,16 @@ func optionWithDefault[T any](option ...T) T {
 
 	return opt
 }
+
+// ParserUrls returns all configured contract parser endpoints in order, with duplicates removed.
+func (config *Config) ParserUrls() []string {
+	var urls []string
+
+	for _, v := range append([]string{config.Poller.RpcUrl}, config.Poller.RpcUrls...) {
+		if len(v) > 0 && !slices.Contains(urls, v) {
+			urls = append(urls, v)
+		}
+	}
+
+	return urls
+}
@@ -1,46 +1,62 @@
 package parsing
 
 import (
+	"bytes"
 	"context"
 	sdtErrors "errors"
-	"math/big"
+	"sort"
 	stdSync "sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"github.com/v3-Swampy/points-service/blockchain"
 	"github.com/v3-Swampy/points-service/sync"
+	"golang.org/x/sync/errgroup"
 )
 
 type EmitOption struct {
 	BufferSize        int           `default:"1024"`
 	IntervalError     time.Duration `default:"5s"`
 	PriceSampleBlocks uint64        `default:"1200"` // about 10 minutes
+	Concurrency       int           `default:"4"`    // maximum number of blocks or pools to query concurrently
+
+	// quarantine pool that keeps failing longer than this, whose events are skipped so that other pools could
+	// move forward, 0 for disabled
+	QuarantineThreshold time.Duration
 }
 
 // Emitter is used to generate event based on polled data from contract parser.
 type Emitter struct {
-	option EmitOption
-	buf    chan sync.BatchEvent
-	vswap  *blockchain.Vswap
-	swappi *blockchain.Swappi
-	logger *logrus.Entry
+	option     EmitOption
+	buf        chan sync.BatchEvent
+	vswap      *blockchain.Vswap
+	quarantine *poolQuarantine // nil if disabled
+	logger     *logrus.Entry
+
+	// samples prices of targets at block, which could be mocked in test
+	sample func(ctx context.Context, bn uint64, targets []priceTarget) ([]priceSample, error)
 }
 
-func NewEmitter(vswap *blockchain.Vswap, swappi *blockchain.Swappi, option ...EmitOption) *Emitter {
+func NewEmitter(vswap *blockchain.Vswap, option ...EmitOption) *Emitter {
 	opt := optionWithDefault(option...)
 
-	return &Emitter{
+	emitter := &Emitter{
 		option: opt,
 		buf:    make(chan sync.BatchEvent, opt.BufferSize),
 		vswap:  vswap,
-		swappi: swappi,
 		logger: logrus.WithField("worker", "sync.emitter"),
 	}
+
+	emitter.sample = emitter.sampleBlock
+
+	if opt.QuarantineThreshold > 0 {
+		emitter.quarantine = newPoolQuarantine(opt.QuarantineThreshold)
+	}
+
+	return emitter
 }
 
 func (emitter *Emitter) Close() {
@@ -59,6 +75,7 @@ func (emitter *Emitter) Run(ctx context.Context, wg *stdSync.WaitGroup, dataCh <
 		case <-ctx.Done():
 			return
 		case data := <-dataCh:
+			pipelineMetrics.Buffer(StagePoller, len(dataCh))
 			emitter.mustEmit(ctx, data)
 		}
 	}
@@ -74,9 +91,11 @@ func (emitter *Emitter) mustEmit(ctx context.Context, data Snapshot) {
 	for {
 		start := time.Now()
 
-		event, err := emitter.emit(ctx, data)
+		event, err := emitter.emitQuarantined(ctx, data)
 		if err != nil {
 			logger.WithError(err).Warn("Failed to emit event")
+			pipelineMetrics.Retry(StageEmitter)
+			pipelineHealth.OnFailure(StageEmitter, err)
 
 			select {
 			case <-ctx.Done():
@@ -85,9 +104,13 @@ func (emitter *Emitter) mustEmit(ctx context.Context, data Snapshot) {
 				logger.Debug("Emitter retry to emit event")
 			}
 		} else {
+			pipelineHealth.OnSuccess(StageEmitter)
+
 			select {
 			case emitter.buf <- event:
 				logger.WithField("elapsed", time.Since(start)).Info("Emitter move forward")
+				pipelineMetrics.Latency(StageEmitter, start)
+				pipelineMetrics.Buffer(StageEmitter, len(emitter.buf))
 				return
 			case <-ctx.Done():
 				return
@@ -96,55 +119,110 @@ func (emitter *Emitter) mustEmit(ctx context.Context, data Snapshot) {
 	}
 }
 
+// emitQuarantined emits events of snapshot excluding quarantined pools, and quarantines pools that keep failing if
+// enabled.
+func (emitter *Emitter) emitQuarantined(ctx context.Context, data Snapshot) (sync.BatchEvent, error) {
+	if emitter.quarantine == nil {
+		return emitter.emit(ctx, data)
+	}
+
+	probe := func(pool PoolData) error {
+		_, err := emitter.emit(ctx, Snapshot{TimeInfo: data.TimeInfo, Pools: []PoolData{pool}})
+		return err
+	}
+
+	data, skipped := emitter.quarantine.filter(data, probe)
+
+	event, err := emitter.emit(ctx, data)
+	if err != nil {
+		emitter.quarantine.onFailure(data, probe)
+		return sync.BatchEvent{}, err
+	}
+
+	emitter.quarantine.onSuccess()
+
+	// recorded along with applied snapshot to replay later
+	event.Skipped = skipped
+
+	return event, nil
+}
+
+// priceTarget is the token to sample price, along with the pool to calculate price in Vswap if not found in Swappi.
+type priceTarget struct {
+	token common.Address
+	pool  common.Address
+}
+
 func (emitter *Emitter) emit(ctx context.Context, data Snapshot) (sync.BatchEvent, error) {
 	logger := emitter.logger.WithField("ts", formatTs(data.Timestamp))
 
 	event := sync.BatchEvent{
-		TimeInfo: data.TimeInfo,
+		TimeInfo:  data.TimeInfo,
+		Snapshots: []sync.TimeInfo{data.TimeInfo},
 	}
 
-	priceCache := make(map[common.Address]decimal.Decimal)
-
+	// get info of pools that have any event concurrently
+	poolInfos := make([]blockchain.PoolInfo, len(data.Pools))
+	group, _ := errgroup.WithContext(ctx)
+	group.SetLimit(max(emitter.option.Concurrency, 1))
 	for i, pool := range data.Pools {
-		// check cancellation
-		select {
-		case <-ctx.Done():
-			return sync.BatchEvent{}, ctx.Err()
-		default:
+		if len(pool.Trades) == 0 && len(pool.Liquidities) == 0 {
+			continue
 		}
 
-		logger.WithField("pool", pool.Address).Debugf("Begin to emit for pool [%v/%v]", i+1, len(data.Pools))
+		group.Go(func() error {
+			logger.WithField("pool", pool.Address).Debugf("Begin to collect pool [%v/%v]", i+1, len(data.Pools))
 
+			info, err := emitter.vswap.GetPoolInfo(pool.Address)
+			if err != nil {
+				return errors.WithMessage(err, "Failed to get pool info")
+			}
+
+			logger.WithField("pool", info).Debug("Pool info retrieved")
+			poolInfos[i] = info
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return sync.BatchEvent{}, err
+	}
+
+	// collect tokens of pools in order, where price of each token sampled only once
+	infos := make(map[common.Address]blockchain.PoolInfo)
+	var targets []priceTarget
+	sampled := make(map[common.Address]bool)
+
+	for i, pool := range data.Pools {
 		if len(pool.Trades) == 0 && len(pool.Liquidities) == 0 {
 			continue
 		}
 
-		// get pool info
-		info, err := emitter.vswap.GetPoolInfo(pool.Address)
-		if err != nil {
-			return sync.BatchEvent{}, errors.WithMessage(err, "Failed to get pool info")
-		}
-
-		logger.WithField("pool", info).Debug("Pool info retrieved")
+		info := poolInfos[i]
+		infos[pool.Address] = info
 
-		// get prices to construct events
-		price0, cached, err := emitter.getPrice(data.MinBlockNumber, data.MaxBlockNumber, pool.Address, info.Token0.Address, priceCache)
-		if err != nil {
-			return sync.BatchEvent{}, errors.WithMessagef(err, "Failed to get price of token0 %v", info.Token0.Symbol)
+		for _, token := range []common.Address{info.Token0.Address, info.Token1.Address} {
+			if !sampled[token] {
+				sampled[token] = true
+				targets = append(targets, priceTarget{token, pool.Address})
+			}
 		}
+	}
 
-		if !cached {
-			logger.WithField("price", price0.Truncate(6)).WithField("token", info.Token0.Symbol).Debug("Token0 price retrieved")
-		}
+	// get prices to construct events
+	prices, err := emitter.samplePrices(ctx, data.TimeInfo, targets)
+	if err != nil {
+		return sync.BatchEvent{}, err
+	}
 
-		price1, cached, err := emitter.getPrice(data.MinBlockNumber, data.MaxBlockNumber, pool.Address, info.Token1.Address, priceCache)
-		if err != nil {
-			return sync.BatchEvent{}, errors.WithMessagef(err, "Failed to get price of token1 %v", info.Token1.Symbol)
+	for _, pool := range data.Pools {
+		info, ok := infos[pool.Address]
+		if !ok {
+			continue
 		}
 
-		if !cached {
-			logger.WithField("price", price1.Truncate(6)).WithField("token", info.Token1.Symbol).Debug("Token1 price retrieved")
-		}
+		price0, price1 := prices[info.Token0.Address].Price, prices[info.Token1.Address].Price
 
 		// trade events
 		for _, v := range pool.Trades {
@@ -153,6 +231,8 @@ func (emitter *Emitter) emit(ctx context.Context, data Snapshot) (sync.BatchEven
 					Timestamp: data.Timestamp,
 					User:      v.UserAddress,
 					Pool:      info,
+					Price0:    price0,
+					Price1:    price1,
 				},
 				Value0: decimal.NewFromBigInt(v.Token0Volume.ToInt(), -int32(info.Token0.Decimals)).Mul(price0),
 				Value1: decimal.NewFromBigInt(v.Token1Volume.ToInt(), -int32(info.Token1.Decimals)).Mul(price1),
@@ -166,6 +246,8 @@ func (emitter *Emitter) emit(ctx context.Context, data Snapshot) (sync.BatchEven
 					Timestamp: data.Timestamp,
 					User:      v.UserAddress,
 					Pool:      info,
+					Price0:    price0,
+					Price1:    price1,
 				},
 				Value0Seconds: decimal.NewFromBigInt(v.Token0LiquiditySeconds.ToInt(), -int32(info.Token0.Decimals)).Mul(price0),
 				Value1Seconds: decimal.NewFromBigInt(v.Token1LiquiditySeconds.ToInt(), -int32(info.Token1.Decimals)).Mul(price1),
@@ -173,71 +255,196 @@ func (emitter *Emitter) emit(ctx context.Context, data Snapshot) (sync.BatchEven
 		}
 	}
 
+	for _, v := range prices {
+		event.Prices = append(event.Prices, v)
+	}
+
+	sort.Slice(event.Prices, func(i, j int) bool {
+		return bytes.Compare(event.Prices[i].Token.Bytes(), event.Prices[j].Token.Bytes()) < 0
+	})
+
 	logger.WithFields(logrus.Fields{
 		"trades":      len(event.Trades),
 		"liquidities": len(event.Liquidities),
+		"prices":      len(event.Prices),
 	}).Debug("Trade and liquidity events generated")
 
 	return event, nil
 }
 
-func (emitter *Emitter) getPrice(minBlockNumber, maxBlockNumber uint64, pool, token common.Address, cache map[common.Address]decimal.Decimal) (decimal.Decimal, bool, error) {
-	if price, ok := cache[token]; ok {
-		return price, true, nil
-	}
+// priceSample is the token price sampled at a block, where zero price indicates no liquidity yet.
+type priceSample struct {
+	price  decimal.Decimal
+	source string
+	route  []common.Address
+	err    error // failed to sample price
+}
 
-	sumPrices := decimal.Zero
-	var count int64
+// samplePrices samples token prices every PriceSampleBlocks blocks backward from the max block number of snapshot,
+// and returns the average price of each token. Reads of all tokens at the same block are aggregated, and at most
+// Concurrency blocks are sampled concurrently.
+//
+// Note, blocks in a wave are sampled before knowing whether a token has liquidity at later blocks of the wave, so
+// failures of token at blocks older than its first zero price sample are ignored, e.g. pool not deployed yet, which
+// keeps the same result as sampling blocks one by one.
+func (emitter *Emitter) samplePrices(ctx context.Context, timeInfo sync.TimeInfo, targets []priceTarget) (map[common.Address]sync.TokenPrice, error) {
+	minBlockNumber, maxBlockNumber := timeInfo.MinBlockNumber, timeInfo.MaxBlockNumber
+
+	results := make(map[common.Address]sync.TokenPrice, len(targets))
+	sumPrices := make(map[common.Address]decimal.Decimal, len(targets))
+	for _, v := range targets {
+		results[v.token] = sync.TokenPrice{
+			Token:     v.token,
+			Timestamp: timeInfo.Timestamp,
+		}
+	}
 
 	// ensure the maxBlockNumber sampled in case that liquidity added at maxBlockNumber
+	var blockNumbers []uint64
 	for bn := maxBlockNumber; bn >= minBlockNumber && bn <= maxBlockNumber; bn -= emitter.option.PriceSampleBlocks {
-		opts := bind.CallOpts{
-			BlockNumber: new(big.Int).SetUint64(bn),
+		blockNumbers = append(blockNumbers, bn)
+	}
+
+	concurrency := max(emitter.option.Concurrency, 1)
+
+	// sample blocks in waves, so that token is not sampled any more once no liquidity at former blocks
+	for start := 0; start < len(blockNumbers) && len(targets) > 0; start += concurrency {
+		wave := blockNumbers[start:min(start+concurrency, len(blockNumbers))]
+
+		samples := make([][]priceSample, len(wave))
+		group, groupCtx := errgroup.WithContext(ctx)
+		for i, bn := range wave {
+			group.Go(func() (err error) {
+				samples[i], err = emitter.sample(groupCtx, bn, targets)
+				return err
+			})
 		}
 
-		price, err := emitter.queryPrice(&opts, pool, token)
-		if err != nil {
-			return decimal.Zero, false, errors.WithMessagef(err, "Failed to sample token price at block %v", bn)
+		if err := group.Wait(); err != nil {
+			return nil, err
+		}
+
+		// accumulate samples in order of block number descending to keep deterministic
+		active := make([]bool, len(targets))
+		for j := range active {
+			active[j] = true
 		}
 
-		// no liquidity yet
-		if price.IsZero() {
-			break
+		for i := range wave {
+			for j, v := range targets {
+				sample := samples[i][j]
+
+				if !active[j] {
+					continue
+				}
+
+				if sample.err != nil {
+					return nil, sample.err
+				}
+
+				// no liquidity yet
+				if sample.price.IsZero() {
+					active[j] = false
+					continue
+				}
+
+				// source and route of the latest sample
+				result := results[v.token]
+				if result.Samples == 0 {
+					result.Source = sample.source
+					result.Route = sample.route
+				}
+
+				sumPrices[v.token] = sumPrices[v.token].Add(sample.price)
+				result.Samples++
+				results[v.token] = result
+			}
+		}
+
+		var remaining []priceTarget
+		for j, v := range targets {
+			if active[j] {
+				remaining = append(remaining, v)
+			}
 		}
 
-		sumPrices = sumPrices.Add(price)
-		count++
+		targets = remaining
 	}
 
-	if count == 0 {
+	for token, result := range results {
+		if result.Samples == 0 {
+			return nil, errors.Errorf("No price sampled for token %v in blocks [%v, %v]", token, minBlockNumber, maxBlockNumber)
+		}
+
+		result.Price = sumPrices[token].Div(decimal.NewFromInt(int64(result.Samples)))
+		results[token] = result
+
+		pipelineMetrics.PriceSamples(result.Samples)
+
 		emitter.logger.WithFields(logrus.Fields{
-			"minBN": minBlockNumber,
-			"maxBN": maxBlockNumber,
-		}).Fatal("No token price sampled")
+			"token":   token,
+			"price":   result.Price.Truncate(6),
+			"samples": result.Samples,
+		}).Debug("Token price retrieved")
 	}
 
-	price := sumPrices.Div(decimal.NewFromInt(count))
-	cache[token] = price
+	return results, nil
+}
+
+// sampleBlock samples prices of all targets at the specified block, and returns samples in order of targets, where
+// failure of any target is recorded in sample rather than returned.
+func (emitter *Emitter) sampleBlock(ctx context.Context, bn uint64, targets []priceTarget) ([]priceSample, error) {
+	// check cancellation
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	reader := emitter.vswap.NewBlockReader(bn)
+
+	pools := make([]common.Address, 0, len(targets))
+	tokens := make([]common.Address, 0, len(targets))
+	for _, v := range targets {
+		pools = append(pools, v.pool)
+		tokens = append(tokens, v.token)
+	}
+
+	// reads will be requested one by one if failed to prefetch
+	if err := reader.Prefetch(pools, tokens); err != nil {
+		emitter.logger.WithError(err).WithField("bn", bn).Warn("Failed to prefetch token prices")
+	}
+
+	samples := make([]priceSample, 0, len(targets))
+	for _, v := range targets {
+		price, source, route, err := emitter.queryPrice(reader, v.pool, v.token)
+		if err != nil {
+			err = errors.WithMessagef(err, "Failed to sample price of token %v at block %v", v.token, bn)
+		}
+
+		samples = append(samples, priceSample{price, source, route, err})
+	}
 
-	return price, false, nil
+	return samples, nil
 }
 
-func (emitter *Emitter) queryPrice(opts *bind.CallOpts, pool, token common.Address) (decimal.Decimal, error) {
+// queryPrice returns the token price along with the price source and token route.
+func (emitter *Emitter) queryPrice(reader *blockchain.BlockReader, pool, token common.Address) (decimal.Decimal, string, []common.Address, error) {
 	// get from swappi
-	price, err := emitter.swappi.GetTokenPriceAuto(opts, token)
+	price, route, err := reader.Swappi.GetTokenPriceAutoWithRoute(reader.Opts(), token)
 	if err == nil {
-		return price, nil
+		return price, sync.PriceSourceSwappi, route, nil
 	}
 
 	if !sdtErrors.Is(err, blockchain.ErrSwappiPairNotFound) {
-		return decimal.Zero, errors.WithMessage(err, "Failed to get token price from Swappi")
+		return decimal.Zero, "", nil, errors.WithMessage(err, "Failed to get token price from Swappi")
 	}
 
 	// get from vswap
-	price, err = emitter.vswap.GetTokenPriceUSDT(opts, pool, token)
+	price, route, err = reader.Vswap.GetTokenPriceUSDTWithRoute(reader.Opts(), pool, token)
 	if err != nil {
-		return decimal.Zero, errors.WithMessage(err, "Failed to get token price from vSwap")
+		return decimal.Zero, "", nil, errors.WithMessage(err, "Failed to get token price from vSwap")
 	}
 
-	return price, nil
+	return price, sync.PriceSourceVswap, route, nil
 }
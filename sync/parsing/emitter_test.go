@@ -0,0 +1,171 @@
+package parsing
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	stdSync "sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"github.com/v3-Swampy/points-service/sync"
+)
+
+// fakePrices returns the price of token at block, where zero price indicates no liquidity yet.
+type fakePrices map[common.Address]func(bn uint64) (decimal.Decimal, error)
+
+// newTestEmitter creates an emitter that samples prices from the given fake prices, and records the sampled blocks.
+func newTestEmitter(concurrency int, prices fakePrices) (*Emitter, *[]uint64) {
+	emitter := NewEmitter(nil, EmitOption{PriceSampleBlocks: 10, Concurrency: concurrency})
+
+	var mu stdSync.Mutex
+	var sampled []uint64
+
+	emitter.sample = func(ctx context.Context, bn uint64, targets []priceTarget) ([]priceSample, error) {
+		mu.Lock()
+		sampled = append(sampled, bn)
+		mu.Unlock()
+
+		samples := make([]priceSample, 0, len(targets))
+		for _, v := range targets {
+			price, err := prices[v.token](bn)
+			samples = append(samples, priceSample{price: price, source: sync.PriceSourceSwappi, err: err})
+		}
+
+		return samples, nil
+	}
+
+	return emitter, &sampled
+}
+
+// serialSamplePrices samples prices block by block, and stops sampling token at the first zero price, which is the
+// baseline of concurrent sampling.
+func serialSamplePrices(timeInfo sync.TimeInfo, step uint64, targets []priceTarget, prices fakePrices) (map[common.Address]sync.TokenPrice, error) {
+	results := make(map[common.Address]sync.TokenPrice)
+	sums := make(map[common.Address]decimal.Decimal)
+
+	for bn := timeInfo.MaxBlockNumber; bn >= timeInfo.MinBlockNumber && bn <= timeInfo.MaxBlockNumber && len(targets) > 0; bn -= step {
+		var remaining []priceTarget
+		for _, v := range targets {
+			price, err := prices[v.token](bn)
+			if err != nil {
+				return nil, err
+			}
+
+			if price.IsZero() {
+				continue
+			}
+
+			result := results[v.token]
+			result.Token, result.Timestamp, result.Source = v.token, timeInfo.Timestamp, sync.PriceSourceSwappi
+			result.Samples++
+			results[v.token] = result
+			sums[v.token] = sums[v.token].Add(price)
+
+			remaining = append(remaining, v)
+		}
+
+		targets = remaining
+	}
+
+	for token, result := range results {
+		result.Price = sums[token].Div(decimal.NewFromInt(int64(result.Samples)))
+		results[token] = result
+	}
+
+	return results, nil
+}
+
+func TestSamplePricesMatchSerial(t *testing.T) {
+	tokenA := common.HexToAddress("0xa")
+	tokenB := common.HexToAddress("0xb")
+	errNotDeployed := errors.New("pool not deployed")
+
+	// blocks sampled: 1090, 1080, ..., 1000
+	timeInfo := sync.TimeInfo{Timestamp: 3600, MinBlockNumber: 1000, MaxBlockNumber: 1090}
+	targets := []priceTarget{{token: tokenA}, {token: tokenB}}
+
+	cases := []struct {
+		name   string
+		prices fakePrices
+		err    bool
+	}{
+		{"always priced", fakePrices{
+			tokenA: func(bn uint64) (decimal.Decimal, error) { return decimal.NewFromInt(int64(bn)), nil },
+			tokenB: func(bn uint64) (decimal.Decimal, error) { return decimal.NewFromInt(2), nil },
+		}, false},
+		{"failed before zero price", fakePrices{
+			// liquidity added at block 1065, and pool not deployed before block 1050
+			tokenA: func(bn uint64) (decimal.Decimal, error) {
+				switch {
+				case bn < 1050:
+					return decimal.Zero, errNotDeployed
+				case bn < 1065:
+					return decimal.Zero, nil
+				default:
+					return decimal.NewFromInt(int64(bn)), nil
+				}
+			},
+			tokenB: func(bn uint64) (decimal.Decimal, error) { return decimal.NewFromInt(2), nil },
+		}, false},
+		{"failed while priced", fakePrices{
+			tokenA: func(bn uint64) (decimal.Decimal, error) {
+				if bn == 1070 {
+					return decimal.Zero, errNotDeployed
+				}
+
+				return decimal.NewFromInt(1), nil
+			},
+			tokenB: func(bn uint64) (decimal.Decimal, error) { return decimal.NewFromInt(2), nil },
+		}, true},
+	}
+
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			expected, err := serialSamplePrices(timeInfo, 10, targets, v.prices)
+			if (err != nil) != v.err {
+				t.Fatalf("Unexpected error of serial sampling %v", err)
+			}
+
+			for _, concurrency := range []int{1, 3, 4, 16} {
+				emitter, _ := newTestEmitter(concurrency, v.prices)
+
+				actual, err := emitter.samplePrices(context.Background(), timeInfo, targets)
+				if (err != nil) != v.err {
+					t.Fatalf("Unexpected error with concurrency %v: %v", concurrency, err)
+				}
+
+				if !reflect.DeepEqual(actual, expected) {
+					t.Fatalf("Unexpected prices with concurrency %v, expected = %+v, actual = %+v",
+						concurrency, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+func TestSamplePricesStopAtZero(t *testing.T) {
+	token := common.HexToAddress("0xa")
+	prices := fakePrices{
+		token: func(bn uint64) (decimal.Decimal, error) {
+			if bn < 1080 {
+				return decimal.Zero, nil
+			}
+
+			return decimal.NewFromInt(1), nil
+		},
+	}
+
+	emitter, sampled := newTestEmitter(2, prices)
+
+	timeInfo := sync.TimeInfo{Timestamp: 3600, MinBlockNumber: 1000, MaxBlockNumber: 1090}
+	if _, err := emitter.samplePrices(context.Background(), timeInfo, []priceTarget{{token: token}}); err != nil {
+		t.Fatalf("Failed to sample prices: %v", err)
+	}
+
+	// no more wave once token has no liquidity
+	if len(*sampled) != 4 {
+		t.Fatalf("Unexpected sampled blocks %v", *sampled)
+	}
+}
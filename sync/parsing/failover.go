@@ -0,0 +1,325 @@
+package parsing
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	stdSync "sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	providers "github.com/openweb3/go-rpc-provider/provider_wrapper"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// endpoint is a contract parser client along with its health status.
+type endpoint struct {
+	url    string
+	client *Client
+
+	healthy         bool
+	latestTimestamp int64
+}
+
+// FailoverClient is a contract parser client across multiple endpoints. Endpoints are health checked when polling
+// the latest timestamp, and requests are served by the healthy endpoint with the highest latest timestamp, or
+// retried on the next endpoint on failure.
+//
+// Besides, data of snapshot could be cross checked between the first two endpoints, and any disagreement will be
+// alerted.
+type FailoverClient struct {
+	mu         stdSync.Mutex
+	endpoints  []*endpoint // in order of preference
+	crossCheck bool
+	logger     *logrus.Entry
+}
+
+func NewFailoverClient(urls []string, crossCheck bool, paging PagingOption, option ...providers.Option) (*FailoverClient, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("Contract parser RPC url not specified")
+	}
+
+	var endpoints []*endpoint
+	for _, url := range urls {
+		client, err := NewClient(url, option...)
+		if err != nil {
+			for _, v := range endpoints {
+				v.client.Close()
+			}
+
+			return nil, err
+		}
+
+		client.Paging = paging
+
+		endpoints = append(endpoints, &endpoint{url: url, client: client, healthy: true})
+	}
+
+	return &FailoverClient{
+		endpoints:  endpoints,
+		crossCheck: crossCheck && len(endpoints) > 1,
+		logger:     logrus.WithField("worker", "sync.parser"),
+	}, nil
+}
+
+func (fc *FailoverClient) Close() {
+	for _, v := range fc.snapshot() {
+		v.client.Close()
+	}
+}
+
+// snapshot returns a copy of endpoints in order of preference, which is safe to iterate while endpoints are
+// re-ordered concurrently.
+func (fc *FailoverClient) snapshot() []*endpoint {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	return slices.Clone(fc.endpoints)
+}
+
+// candidates returns endpoints in order of preference that could serve data of the given timestamp, i.e. healthy
+// endpoints at first, and then unhealthy ones as the last resort.
+//
+// Note, stale endpoints that not synced to the given timestamp are excluded, since they may return empty data
+// without any error, and it returns error if no endpoint synced yet.
+func (fc *FailoverClient) candidates(timestamp int64) ([]*endpoint, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	var preferred, others []*endpoint
+	for _, v := range fc.endpoints {
+		if v.latestTimestamp < timestamp {
+			continue
+		}
+
+		if v.healthy {
+			preferred = append(preferred, v)
+		} else {
+			others = append(others, v)
+		}
+	}
+
+	if len(preferred)+len(others) == 0 {
+		return nil, errors.Errorf("No contract parser endpoint synced to %v", formatTs(timestamp))
+	}
+
+	return append(preferred, others...), nil
+}
+
+func (fc *FailoverClient) markUnhealthy(e *endpoint, err error) {
+	fc.mu.Lock()
+	e.healthy = false
+	fc.mu.Unlock()
+
+	fc.logger.WithError(err).WithField("url", e.url).Warn("Contract parser endpoint failed")
+}
+
+// failoverCall calls endpoints in order of preference until succeeded.
+func failoverCall[T any](fc *FailoverClient, timestamp int64, fn func(client *Client) (T, error)) (T, error) {
+	var result T
+
+	candidates, err := fc.candidates(timestamp)
+	if err != nil {
+		return result, err
+	}
+
+	for _, v := range candidates {
+		if result, err = fn(v.client); err == nil {
+			return result, nil
+		}
+
+		fc.markUnhealthy(v, err)
+	}
+
+	return result, errors.WithMessage(err, "All contract parser endpoints failed")
+}
+
+// failoverStream streams pages from endpoints in order of preference until succeeded. Note, it fails over to the
+// next endpoint only if no page emitted yet, since pages emitted could not be revoked, and errors of callback are
+// returned directly without failover.
+func failoverStream[T any](fc *FailoverClient, timestamp int64,
+	fn func(client *Client, callback func(page []T) error) error, callback func(page []T) error) error {
+	candidates, err := fc.candidates(timestamp)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range candidates {
+		var emitted bool
+		var callbackErr error
+
+		err = fn(v.client, func(page []T) error {
+			emitted = true
+			callbackErr = callback(page)
+			return callbackErr
+		})
+		if err == nil || callbackErr != nil {
+			return err
+		}
+
+		fc.markUnhealthy(v, err)
+
+		if emitted {
+			return errors.WithMessage(err, "Contract parser endpoint failed during streaming")
+		}
+	}
+
+	return errors.WithMessage(err, "All contract parser endpoints failed")
+}
+
+// checkedCall calls the preferred endpoint, and cross checks the result with the next endpoint if enabled.
+func checkedCall[T any](fc *FailoverClient, timestamp int64, desc string, fn func(client *Client) ([]T, error)) ([]T, error) {
+	result, err := failoverCall(fc, timestamp, fn)
+	if err != nil || !fc.crossCheck {
+		return result, err
+	}
+
+	// cross check only if the second endpoint is healthy and synced
+	candidates, err := fc.candidates(timestamp)
+	if err != nil || len(candidates) < 2 {
+		return result, nil
+	}
+
+	fc.mu.Lock()
+	available := candidates[1].healthy
+	fc.mu.Unlock()
+
+	if !available {
+		return result, nil
+	}
+
+	other, err := fn(candidates[1].client)
+	if err != nil {
+		fc.logger.WithError(err).WithField("url", candidates[1].url).Debug("Failed to cross check contract parser endpoint")
+		return result, nil
+	}
+
+	if !sameElements(result, other) {
+		pipelineAlert.OnDisagreement(
+			fmt.Sprintf("Contract parser endpoints disagree on %v of snapshot %v between %v and %v",
+				desc, formatTs(timestamp), candidates[0].url, candidates[1].url),
+		)
+	}
+
+	return result, nil
+}
+
+// sameElements returns whether the given data contains the same elements regardless of order, since endpoints
+// may return data of snapshot in different order.
+func sameElements[T any](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	counts := make(map[string]int, len(a))
+	for _, v := range a {
+		counts[elementKey(v)]++
+	}
+
+	for _, v := range b {
+		key := elementKey(v)
+		if counts[key] == 0 {
+			return false
+		}
+
+		counts[key]--
+	}
+
+	return true
+}
+
+// elementKey returns the JSON encoded element as key, which compares big integers by value rather than pointer.
+func elementKey(v any) string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%+v", v)
+	}
+
+	return string(encoded)
+}
+
+// LatestTimestamp health checks all endpoints, and returns the highest latest timestamp among healthy endpoints.
+func (fc *FailoverClient) LatestTimestamp() (int64, error) {
+	endpoints := fc.snapshot()
+	latests := make([]int64, len(endpoints))
+	errs := make([]error, len(endpoints))
+
+	var group errgroup.Group
+	for i, v := range endpoints {
+		group.Go(func() error {
+			latests[i], errs[i] = v.client.LatestTimestamp()
+			return nil
+		})
+	}
+	group.Wait()
+
+	fc.mu.Lock()
+
+	var lastErr error
+	for i, v := range endpoints {
+		if errs[i] != nil {
+			v.healthy = false
+			lastErr = errs[i]
+			fc.logger.WithError(errs[i]).WithField("url", v.url).Warn("Contract parser endpoint is unhealthy")
+		} else {
+			v.healthy = true
+			v.latestTimestamp = latests[i]
+		}
+	}
+
+	// prefer healthy endpoint with higher latest timestamp, and keep configured order if equal
+	sort.SliceStable(fc.endpoints, func(i, j int) bool {
+		if fc.endpoints[i].healthy != fc.endpoints[j].healthy {
+			return fc.endpoints[i].healthy
+		}
+
+		return fc.endpoints[i].latestTimestamp > fc.endpoints[j].latestTimestamp
+	})
+
+	best := fc.endpoints[0]
+	healthy, latest := best.healthy, best.latestTimestamp
+
+	fc.mu.Unlock()
+
+	if !healthy {
+		return 0, errors.WithMessage(lastErr, "All contract parser endpoints are unhealthy")
+	}
+
+	return latest, nil
+}
+
+func (fc *FailoverClient) FirstTimestamp() (int64, error) {
+	return failoverCall(fc, 0, (*Client).FirstTimestamp)
+}
+
+func (fc *FailoverClient) SnapshotIntervalSecs() (int64, error) {
+	return failoverCall(fc, 0, (*Client).SnapshotIntervalSecs)
+}
+
+func (fc *FailoverClient) GetTradeDataAll(pool common.Address, timestamp int64) ([]TradeData, error) {
+	return checkedCall(fc, timestamp, fmt.Sprintf("trade data of pool %v", pool), func(client *Client) ([]TradeData, error) {
+		return client.GetTradeDataAll(pool, timestamp)
+	})
+}
+
+func (fc *FailoverClient) GetLiquidityDataAll(pool common.Address, timestamp int64) ([]LiquidityData, error) {
+	return checkedCall(fc, timestamp, fmt.Sprintf("liquidity data of pool %v", pool), func(client *Client) ([]LiquidityData, error) {
+		return client.GetLiquidityDataAll(pool, timestamp)
+	})
+}
+
+// StreamTradeData emits trade data of pool in snapshot page by page. Note, data is not cross checked.
+func (fc *FailoverClient) StreamTradeData(pool common.Address, timestamp int64, callback func(page []TradeData) error) error {
+	return failoverStream(fc, timestamp, func(client *Client, callback func(page []TradeData) error) error {
+		return client.StreamTradeData(pool, timestamp, callback)
+	}, callback)
+}
+
+// StreamLiquidityData emits liquidity data of pool in snapshot page by page. Note, data is not cross checked.
+func (fc *FailoverClient) StreamLiquidityData(pool common.Address, timestamp int64, callback func(page []LiquidityData) error) error {
+	return failoverStream(fc, timestamp, func(client *Client, callback func(page []LiquidityData) error) error {
+		return client.StreamLiquidityData(pool, timestamp, callback)
+	}, callback)
+}
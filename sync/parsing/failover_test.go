@@ -0,0 +1,188 @@
+package parsing
+
+import (
+	"errors"
+	"math/big"
+	"math/rand/v2"
+	stdSync "sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// newFakeNode creates a contract parser node synced to the given latest timestamp, which returns trade data
+// of the given users in order, or error if failData is true.
+func newFakeNode(t *testing.T, latest int64, failData bool, users ...string) string {
+	return newFakeParser(t, func(method string, params []any) (any, error) {
+		switch method {
+		case "latestTimestamp":
+			return latest, nil
+		case "getHourlyTradeData", "getHourlyLiquidityData":
+			if failData {
+				return nil, errors.New("node failure")
+			}
+
+			data := make([]map[string]any, 0, len(users))
+			for _, v := range users {
+				data = append(data, map[string]any{"user": v})
+			}
+
+			return map[string]any{"total": len(data), "data": data}, nil
+		default:
+			return nil, errors.New("method not found")
+		}
+	}).URL
+}
+
+func TestFailoverClientPreferLatest(t *testing.T) {
+	stale := newFakeNode(t, 100, false, "a")
+	latest := newFakeNode(t, 200, false, "a", "b")
+
+	client, err := NewFailoverClient([]string{stale, latest}, false, PagingOption{})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if ts, err := client.LatestTimestamp(); err != nil || ts != 200 {
+		t.Fatalf("Unexpected latest timestamp %v, err = %v", ts, err)
+	}
+
+	data, err := client.GetTradeDataAll(common.Address{}, 200)
+	if err != nil || len(data) != 2 {
+		t.Fatalf("Unexpected trade data %+v, err = %v", data, err)
+	}
+}
+
+func TestFailoverClientExcludeStale(t *testing.T) {
+	stale := newFakeNode(t, 100, false)
+	failed := newFakeNode(t, 200, true)
+
+	client, err := NewFailoverClient([]string{stale, failed}, false, PagingOption{})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if _, err = client.LatestTimestamp(); err != nil {
+		t.Fatalf("Failed to get latest timestamp: %v", err)
+	}
+
+	// stale node returns empty data without error, which should never be used
+	if data, err := client.GetTradeDataAll(common.Address{}, 200); err == nil {
+		t.Fatalf("Data served by stale node %+v", data)
+	}
+
+	if data, err := client.GetTradeDataAll(common.Address{}, 300); err == nil {
+		t.Fatalf("Data served while no node synced %+v", data)
+	}
+
+	// stale node is still able to serve earlier snapshots
+	if _, err := client.GetTradeDataAll(common.Address{}, 100); err != nil {
+		t.Fatalf("Failed to get trade data of synced snapshot: %v", err)
+	}
+}
+
+func TestFailoverClientConcurrent(t *testing.T) {
+	// endpoints are re-ordered frequently since latest timestamps change randomly
+	var urls []string
+	for range 3 {
+		urls = append(urls, newFakeParser(t, func(method string, params []any) (any, error) {
+			if method == "latestTimestamp" {
+				return 100 + rand.Int64N(100), nil
+			}
+
+			return map[string]any{"total": 1, "data": []any{map[string]any{"user": "a"}}}, nil
+		}).URL)
+	}
+
+	client, err := NewFailoverClient(urls, true, PagingOption{})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	var wg stdSync.WaitGroup
+	for range 50 {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := client.LatestTimestamp(); err != nil {
+				t.Errorf("Failed to get latest timestamp: %v", err)
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			client.GetTradeDataAll(common.Address{}, 100)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestSameElements(t *testing.T) {
+	trade := func(user string, volume int64) TradeData {
+		return TradeData{UserAddress: user, Token0Volume: (*hexutil.Big)(big.NewInt(volume))}
+	}
+
+	cases := []struct {
+		a, b     []TradeData
+		expected bool
+	}{
+		{nil, nil, true},
+		{nil, []TradeData{}, true},
+		{[]TradeData{trade("a", 1), trade("b", 2)}, []TradeData{trade("b", 2), trade("a", 1)}, true},
+		{[]TradeData{trade("a", 1), trade("a", 1)}, []TradeData{trade("a", 1), trade("b", 1)}, false},
+		{[]TradeData{trade("a", 1)}, []TradeData{trade("a", 2)}, false},
+		{[]TradeData{trade("a", 1)}, []TradeData{trade("a", 1), trade("a", 1)}, false},
+	}
+
+	for i, v := range cases {
+		if actual := sameElements(v.a, v.b); actual != v.expected {
+			t.Errorf("Case %v: expected = %v, actual = %v", i, v.expected, actual)
+		}
+	}
+}
+
+func TestFailoverClientStream(t *testing.T) {
+	failed := newFakeNode(t, 200, true)
+	healthy := newFakeNode(t, 200, false, "a", "b")
+
+	client, err := NewFailoverClient([]string{failed, healthy}, false, PagingOption{})
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if _, err = client.LatestTimestamp(); err != nil {
+		t.Fatalf("Failed to get latest timestamp: %v", err)
+	}
+
+	// fail over to the next endpoint since no page emitted yet
+	var users []string
+	err = client.StreamLiquidityData(common.Address{}, 200, func(page []LiquidityData) error {
+		for _, v := range page {
+			users = append(users, v.UserAddress)
+		}
+
+		return nil
+	})
+	if err != nil || len(users) != 2 || users[0] != "a" || users[1] != "b" {
+		t.Fatalf("Unexpected liquidity data %v, err = %v", users, err)
+	}
+
+	// errors of callback returned without failover
+	var pages int
+	err = client.StreamTradeData(common.Address{}, 200, func(page []TradeData) error {
+		pages++
+		return errors.New("callback failure")
+	})
+	if err == nil || pages != 1 {
+		t.Fatalf("Unexpected failover on callback error, pages = %v, err = %v", pages, err)
+	}
+}
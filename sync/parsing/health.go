@@ -0,0 +1,94 @@
+package parsing
+
+import (
+	"sync"
+	"time"
+)
+
+// StageStatus is the health status of a sync pipeline stage.
+type StageStatus struct {
+	Stage         string     `json:"stage"`
+	Retrying      bool       `json:"retrying"`                // whether stage keeps retrying due to failures
+	Failures      int        `json:"failures"`                // number of continuous failures
+	FailedAt      *time.Time `json:"failedAt,omitempty"`      // time of the first continuous failure
+	LastError     string     `json:"lastError,omitempty"`     // the last error if retrying
+	LastSuccessAt *time.Time `json:"lastSuccessAt,omitempty"` // time of the last success, nil if never succeeded
+}
+
+// RetryingFor returns the elapsed time since stage retried, or 0 if not retrying.
+func (status StageStatus) RetryingFor(now time.Time) time.Duration {
+	if !status.Retrying {
+		return 0
+	}
+
+	return now.Sub(*status.FailedAt)
+}
+
+// stageHealth tracks health status of all stages in sync pipeline, which is thread safe.
+type stageHealth struct {
+	mu     sync.Mutex
+	stages map[string]*StageStatus
+}
+
+var pipelineHealth = stageHealth{
+	stages: make(map[string]*StageStatus),
+}
+
+func (health *stageHealth) status(stage string) *StageStatus {
+	status, ok := health.stages[stage]
+	if !ok {
+		status = &StageStatus{Stage: stage}
+		health.stages[stage] = status
+	}
+
+	return status
+}
+
+// OnSuccess resets the retrying status of stage.
+func (health *stageHealth) OnSuccess(stage string) {
+	pipelineAlert.OnSuccess(stage)
+
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	status := health.status(stage)
+	status.Retrying = false
+	status.Failures = 0
+	status.FailedAt = nil
+	status.LastError = ""
+
+	now := time.Now()
+	status.LastSuccessAt = &now
+}
+
+// OnFailure marks stage as retrying.
+func (health *stageHealth) OnFailure(stage string, err error) {
+	pipelineAlert.OnRetry(stage, err)
+
+	health.mu.Lock()
+	defer health.mu.Unlock()
+
+	status := health.status(stage)
+	if !status.Retrying {
+		now := time.Now()
+		status.Retrying = true
+		status.FailedAt = &now
+	}
+
+	status.Failures++
+	status.LastError = err.Error()
+}
+
+// PipelineStatus returns the health status of poller, emitter and batcher in order. Note, stage that not reported
+// yet, e.g. during startup, is regarded as healthy.
+func PipelineStatus() []StageStatus {
+	pipelineHealth.mu.Lock()
+	defer pipelineHealth.mu.Unlock()
+
+	result := make([]StageStatus, 0, 3)
+	for _, stage := range []string{StagePoller, StageEmitter, StageBatcher} {
+		result = append(result, *pipelineHealth.status(stage))
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+package parsing
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/metrics"
+)
+
+// Names of sync pipeline stages, which are used as part of metrics names.
+const (
+	StagePoller  = "poller"
+	StageEmitter = "emitter"
+	StageBatcher = "batcher"
+)
+
+// syncMetrics reports metrics of sync pipeline, which are created lazily so that metrics configurations could be
+// loaded before any metric created.
+type syncMetrics struct{}
+
+var pipelineMetrics syncMetrics
+
+// Lag updates the lag in seconds between the latest timestamp of contract parser and the applied timestamp.
+func (syncMetrics) Lag(latestTimestamp, timestamp int64) {
+	metrics.GetOrRegisterGauge("sync/poller/lag").Update(max(latestTimestamp-timestamp, 0))
+}
+
+// Latency updates the elapsed time to process a snapshot or batch in stage.
+func (syncMetrics) Latency(stage string, start time.Time) {
+	metrics.GetOrRegisterTimer("sync/%v/latency", stage).UpdateSince(start)
+}
+
+// Retry increases the number of retries due to failure in stage.
+func (syncMetrics) Retry(stage string) {
+	metrics.GetOrRegisterCounter("sync/%v/retries", stage).Inc(1)
+}
+
+// Buffer updates the number of pending items in channel buffer of stage.
+func (syncMetrics) Buffer(stage string, size int) {
+	metrics.GetOrRegisterGauge("sync/%v/buffer", stage).Update(int64(size))
+}
+
+// PriceSamples updates the number of blocks sampled for token price.
+func (syncMetrics) PriceSamples(samples int) {
+	metrics.GetOrRegisterHistogram("sync/emitter/price/samples").Update(int64(samples))
+}
+
+// BatchSize updates the number of trade and liquidity events handled in batch.
+func (syncMetrics) BatchSize(trades, liquidities int) {
+	metrics.GetOrRegisterHistogram("sync/batcher/size/trades").Update(int64(trades))
+	metrics.GetOrRegisterHistogram("sync/batcher/size/liquidities").Update(int64(liquidities))
+}
+
+// syncLag tracks the latest timestamp of contract parser and the timestamp of the last applied snapshot, so that
+// lag is measured against snapshots committed to database rather than polled ones, which may be still buffered in
+// pipeline or failed to apply.
+type syncLag struct {
+	latest  atomic.Int64 // latest timestamp of contract parser
+	applied atomic.Int64 // timestamp of the last applied snapshot
+}
+
+var pipelineLag syncLag
+
+// OnLatest updates lag once the latest timestamp of contract parser polled.
+func (sl *syncLag) OnLatest(timestamp int64) {
+	sl.latest.Store(timestamp)
+	sl.update()
+}
+
+// OnApplied updates lag once snapshots applied, i.e. committed to database.
+func (sl *syncLag) OnApplied(timestamp int64) {
+	sl.applied.Store(timestamp)
+	sl.update()
+}
+
+func (sl *syncLag) update() {
+	latest, applied := sl.latest.Load(), sl.applied.Load()
+	if latest == 0 || applied == 0 {
+		return
+	}
+
+	pipelineMetrics.Lag(latest, applied)
+	pipelineAlert.OnLag(time.Duration(max(latest-applied, 0)) * time.Second)
+}
@@ -0,0 +1,30 @@
+package parsing
+
+import (
+	"testing"
+
+	"github.com/Conflux-Chain/go-conflux-util/metrics"
+)
+
+func TestSyncLagOnApplied(t *testing.T) {
+	var sl syncLag
+	gauge := metrics.GetOrRegisterGauge("sync/poller/lag")
+
+	// resumed from the last applied snapshot
+	sl.OnApplied(3600)
+	sl.OnLatest(18000)
+	if v := gauge.Value(); v != 14400 {
+		t.Fatalf("Expected lag 14400, got %v", v)
+	}
+
+	// snapshots polled but not applied yet
+	sl.OnLatest(21600)
+	if v := gauge.Value(); v != 18000 {
+		t.Fatalf("Expected lag 18000, got %v", v)
+	}
+
+	sl.OnApplied(21600)
+	if v := gauge.Value(); v != 0 {
+		t.Fatalf("Expected lag 0, got %v", v)
+	}
+}
@@ -3,14 +3,16 @@ package parsing
 import (
 	"context"
 	"fmt"
-	"sync"
+	stdSync "sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	providers "github.com/openweb3/go-rpc-provider/provider_wrapper"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"github.com/v3-Swampy/points-service/blockchain"
 	"github.com/v3-Swampy/points-service/blockchain/scan"
+	"github.com/v3-Swampy/points-service/sync"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -19,6 +21,12 @@ type PollOption struct {
 	IntervalError time.Duration `default:"5s"`
 	IntervalIdle  time.Duration `default:"3s"`
 
+	// cross check data of snapshot between contract parser endpoints, and alert on disagreement
+	CrossCheck bool
+
+	// page size and limit to query trade and liquidity data
+	Paging PagingOption
+
 	RPC  providers.Option
 	Scan scan.Option
 }
@@ -26,27 +34,33 @@ type PollOption struct {
 // Poller is used to poll trade and liquidity data from contract parser.
 type Poller struct {
 	option        PollOption
-	client        *Client
-	scan          *scan.Api
+	client        *FailoverClient
+	resolver      blockchain.BlockResolver
 	buf           chan Snapshot
 	nextTimestamp int64
+	lastMaxBN     uint64 // max block number of the last processed snapshot
+	resumed       bool   // whether resumed from the last processed snapshot
 	intervalSecs  int64
 	pools         []common.Address
 	logger        *logrus.Entry
 }
 
-// NewPoller creates a new poller.
+// NewPoller creates a new poller, which resumes from the given last processed snapshot. Multiple contract parser
+// endpoints could be specified for failover.
 //
-// If the given lastTimestamp is 0, then retrieve the first timestamp from contract parser.
+// If the given last timestamp is 0, then retrieve the first timestamp from contract parser. If the given last max
+// block number is 0, then resolve the min block number of next snapshot by time.
 //
 // Note, it returns error if the given pools is empty.
-func NewPoller(rpcUrl, scanUrl string, lastTimestamp int64, pools []common.Address, option ...PollOption) (*Poller, error) {
+func NewPoller(rpcUrls []string, resolver blockchain.BlockResolver, last sync.TimeInfo, pools []common.Address, option ...PollOption) (*Poller, error) {
 	if len(pools) == 0 {
 		return nil, errors.New("Pools not specified")
 	}
 
+	opt := optionWithDefault(option...)
+
 	// init rpc client
-	client, err := NewClient(rpcUrl)
+	client, err := NewFailoverClient(rpcUrls, opt.CrossCheck, opt.Paging, opt.RPC)
 	if err != nil {
 		return nil, errors.WithMessage(err, "Failed to create client")
 	}
@@ -58,22 +72,22 @@ func NewPoller(rpcUrl, scanUrl string, lastTimestamp int64, pools []common.Addre
 
 	// retrieve first timestamp
 	var nextTimestamp int64
-	if lastTimestamp == 0 {
+	if last.Timestamp == 0 {
 		if nextTimestamp, err = client.FirstTimestamp(); err != nil {
 			return nil, errors.WithMessage(err, "Failed to poll first timestamp")
 		}
 	} else {
-		nextTimestamp = lastTimestamp + intervalSecs
+		nextTimestamp = last.Timestamp + intervalSecs
 	}
 
-	opt := optionWithDefault(option...)
-
 	return &Poller{
 		option:        opt,
 		client:        client,
-		scan:          scan.NewApi(scanUrl, opt.Scan),
+		resolver:      resolver,
 		buf:           make(chan Snapshot, opt.BufferSize),
 		nextTimestamp: nextTimestamp,
+		lastMaxBN:     last.MaxBlockNumber,
+		resumed:       last.Timestamp > 0,
 		intervalSecs:  intervalSecs,
 		pools:         pools,
 		logger:        logrus.WithField("worker", "sync.poller"),
@@ -89,16 +103,22 @@ func (poller *Poller) Ch() <-chan Snapshot {
 	return poller.buf
 }
 
-func (poller *Poller) Run(ctx context.Context, wg *sync.WaitGroup) {
+func (poller *Poller) Run(ctx context.Context, wg *stdSync.WaitGroup) {
 	defer wg.Done()
 
 	timestamp := poller.nextTimestamp
-	poller.logger.WithField("ts", formatTs(timestamp)).Info("Poller started")
+	poller.logger.WithFields(logrus.Fields{
+		"ts":        formatTs(timestamp),
+		"lastMaxBN": poller.lastMaxBN,
+	}).Info("Poller started")
 
 	ticker := time.NewTicker(time.Millisecond)
 	defer ticker.Stop()
 
-	var lastMaxBlockNumber uint64
+	lastMaxBlockNumber := poller.lastMaxBN
+
+	// snapshots before the next one are applied already
+	pipelineLag.OnApplied(timestamp - poller.intervalSecs)
 
 	for {
 		select {
@@ -112,11 +132,17 @@ func (poller *Poller) Run(ctx context.Context, wg *sync.WaitGroup) {
 			data, ok, err := poller.poll(timestamp, lastMaxBlockNumber)
 			if err != nil {
 				logger.WithError(err).Warn("Failed to poll data from contract parser")
+				pipelineMetrics.Retry(StagePoller)
+				pipelineHealth.OnFailure(StagePoller, err)
 				ticker.Reset(poller.option.IntervalError)
 			} else if ok {
+				pipelineHealth.OnSuccess(StagePoller)
+
 				select {
 				case poller.buf <- data:
 					logger.WithField("elapsed", time.Since(start)).Info("Poller move forward")
+					pipelineMetrics.Latency(StagePoller, start)
+					pipelineMetrics.Buffer(StagePoller, len(poller.buf))
 					timestamp += poller.intervalSecs
 					lastMaxBlockNumber = data.MaxBlockNumber
 				case <-ctx.Done():
@@ -125,6 +151,7 @@ func (poller *Poller) Run(ctx context.Context, wg *sync.WaitGroup) {
 				ticker.Reset(time.Millisecond)
 			} else {
 				logger.Debug("Poller is idle")
+				pipelineHealth.OnSuccess(StagePoller)
 				ticker.Reset(poller.option.IntervalIdle)
 			}
 		}
@@ -138,6 +165,8 @@ func (poller *Poller) poll(timestamp int64, lastMaxBlockNumber uint64) (Snapshot
 		return Snapshot{}, false, errors.WithMessage(err, "Failed to poll latest timestamp")
 	}
 
+	pipelineLag.OnLatest(latestTimestamp)
+
 	if timestamp > latestTimestamp {
 		return Snapshot{}, false, nil
 	}
@@ -175,7 +204,7 @@ func (poller *Poller) poll(timestamp int64, lastMaxBlockNumber uint64) (Snapshot
 		})
 	}
 
-	// poll min block number from scan
+	// resolve min block number by time
 	var minBlockNumber uint64
 	group.Go(func() error {
 		if lastMaxBlockNumber > 0 {
@@ -188,13 +217,13 @@ func (poller *Poller) poll(timestamp int64, lastMaxBlockNumber uint64) (Snapshot
 			startTime = timestamp - poller.intervalSecs
 		}
 
-		bn, err := poller.scan.GetBlockNumberByTime(startTime, true)
+		bn, err := poller.resolver.GetBlockNumberByTime(startTime, true)
 		if err != nil {
 			return errors.WithMessage(err, "Failed to query min block number")
 		}
 
 		if bn == 0 {
-			return errors.Errorf("Failed to get min block number from scan, 0 returned by timestamp %v", startTime)
+			return errors.Errorf("Failed to get min block number, 0 returned by timestamp %v", startTime)
 		}
 
 		minBlockNumber = bn
@@ -202,16 +231,16 @@ func (poller *Poller) poll(timestamp int64, lastMaxBlockNumber uint64) (Snapshot
 		return nil
 	})
 
-	// poll max block number from scan
+	// resolve max block number by time
 	var maxBlockNumber uint64
 	group.Go(func() error {
-		bn, err := poller.scan.GetBlockNumberByTime(timestamp-1, false)
+		bn, err := poller.resolver.GetBlockNumberByTime(timestamp-1, false)
 		if err != nil {
 			return errors.WithMessage(err, "Failed to query max block number")
 		}
 
 		if bn == 0 {
-			return errors.Errorf("Failed to get max block number from scan, 0 returned by timestamp %v", timestamp)
+			return errors.Errorf("Failed to get max block number, 0 returned by timestamp %v", timestamp)
 		}
 
 		maxBlockNumber = bn
@@ -235,7 +264,7 @@ func (poller *Poller) poll(timestamp int64, lastMaxBlockNumber uint64) (Snapshot
 		poller.logger.WithFields(logrus.Fields{
 			"min": result.MinBlockNumber,
 			"max": result.MaxBlockNumber,
-		}).Fatal("Invalid block number retrieved from scan")
+		}).Fatal("Invalid block number resolved by time")
 	}
 
 	return result, true, nil
@@ -0,0 +1,138 @@
+package parsing
+
+import (
+	"time"
+
+	"github.com/Conflux-Chain/go-conflux-util/health"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/v3-Swampy/points-service/sync"
+)
+
+// poolQuarantine excludes pools that keep failing to emit events, so that other pools could move forward.
+//
+// Once failed to emit a snapshot, each pool of snapshot is probed individually. Pools that keep failing longer than
+// the threshold are quarantined, unless all pools failed, which indicates a general failure, e.g. RPC unavailable.
+// Quarantined pools are probed again for each snapshot, and released once succeeded.
+//
+// Note, events of quarantined pools are skipped, which are recorded along with the applied snapshot, so that they
+// could be replayed by backfill later. Besides, quarantined pools are not persisted, and will be detected again
+// after restart.
+type poolQuarantine struct {
+	threshold   time.Duration
+	suspects    map[common.Address]*health.TimedCounter // pool => failure counter
+	quarantined map[common.Address]error                // pool => the last error
+	logger      *logrus.Entry
+}
+
+func newPoolQuarantine(threshold time.Duration) *poolQuarantine {
+	return &poolQuarantine{
+		threshold:   threshold,
+		suspects:    make(map[common.Address]*health.TimedCounter),
+		quarantined: make(map[common.Address]error),
+		logger:      logrus.WithField("worker", "sync.emitter"),
+	}
+}
+
+// filter probes quarantined pools that have any event in snapshot, and releases pools that succeeded. It returns
+// the snapshot excluding pools still quarantined, along with the skipped pools.
+func (pq *poolQuarantine) filter(data Snapshot, probe func(pool PoolData) error) (Snapshot, []sync.SkippedPool) {
+	if len(pq.quarantined) == 0 {
+		return data, nil
+	}
+
+	var skipped []sync.SkippedPool
+	result := Snapshot{TimeInfo: data.TimeInfo}
+	for _, pool := range data.Pools {
+		if _, ok := pq.quarantined[pool.Address]; !ok || !hasEvents(pool) {
+			result.Pools = append(result.Pools, pool)
+			continue
+		}
+
+		err := probe(pool)
+		pipelineAlert.OnQuarantine(pool.Address, err)
+
+		if err == nil {
+			delete(pq.quarantined, pool.Address)
+			result.Pools = append(result.Pools, pool)
+			continue
+		}
+
+		pq.quarantined[pool.Address] = err
+		skipped = append(skipped, sync.SkippedPool{
+			TimeInfo:    data.TimeInfo,
+			Pool:        pool.Address,
+			Trades:      len(pool.Trades),
+			Liquidities: len(pool.Liquidities),
+			Reason:      err.Error(),
+		})
+
+		pq.logger.WithError(err).WithFields(logrus.Fields{
+			"ts":          formatTs(data.Timestamp),
+			"pool":        pool.Address,
+			"trades":      len(pool.Trades),
+			"liquidities": len(pool.Liquidities),
+		}).Error("Events of quarantined pool skipped")
+	}
+
+	return result, skipped
+}
+
+// onFailure probes all pools that have any event in the failed snapshot, and quarantines pools that keep failing
+// longer than threshold.
+func (pq *poolQuarantine) onFailure(data Snapshot, probe func(pool PoolData) error) {
+	var active []PoolData
+	for _, pool := range data.Pools {
+		if hasEvents(pool) {
+			active = append(active, pool)
+		}
+	}
+
+	failed := make(map[common.Address]error)
+	for _, pool := range active {
+		if err := probe(pool); err != nil {
+			failed[pool.Address] = err
+		}
+	}
+
+	// not pool specific, e.g. RPC unavailable
+	if len(failed) == 0 || len(failed) == len(active) {
+		pq.onSuccess()
+		return
+	}
+
+	for pool := range pq.suspects {
+		if _, ok := failed[pool]; !ok {
+			delete(pq.suspects, pool)
+		}
+	}
+
+	for pool, err := range failed {
+		counter, ok := pq.suspects[pool]
+		if !ok {
+			counter = health.NewTimedCounter(health.TimedCounterConfig{Threshold: pq.threshold})
+			pq.suspects[pool] = counter
+		}
+
+		if unhealthy, _, elapsed := counter.OnFailure(); unhealthy {
+			delete(pq.suspects, pool)
+			pq.quarantined[pool] = err
+
+			pq.logger.WithError(err).WithFields(logrus.Fields{
+				"ts":      formatTs(data.Timestamp),
+				"pool":    pool,
+				"elapsed": elapsed,
+			}).Error("Pool quarantined")
+			pipelineAlert.OnQuarantine(pool, err)
+		}
+	}
+}
+
+// onSuccess resets pools that suspected to fail.
+func (pq *poolQuarantine) onSuccess() {
+	clear(pq.suspects)
+}
+
+func hasEvents(pool PoolData) bool {
+	return len(pool.Trades) > 0 || len(pool.Liquidities) > 0
+}
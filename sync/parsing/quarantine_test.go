@@ -0,0 +1,116 @@
+package parsing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+var (
+	testPoolA = common.HexToAddress("0xa")
+	testPoolB = common.HexToAddress("0xb")
+	testPoolC = common.HexToAddress("0xc") // no event
+)
+
+func newQuarantineSnapshot() Snapshot {
+	return Snapshot{Pools: []PoolData{
+		{Address: testPoolA, Trades: []TradeData{{UserAddress: "0x1"}}},
+		{Address: testPoolB, Liquidities: []LiquidityData{{UserAddress: "0x2"}}},
+		{Address: testPoolC},
+	}}
+}
+
+// failingProbe fails for the given pools.
+func failingProbe(pools ...common.Address) func(pool PoolData) error {
+	return func(pool PoolData) error {
+		for _, v := range pools {
+			if pool.Address == v {
+				return errors.New("probe failed")
+			}
+		}
+
+		return nil
+	}
+}
+
+func assertPools(t *testing.T, data Snapshot, expected ...common.Address) {
+	t.Helper()
+
+	if len(data.Pools) != len(expected) {
+		t.Fatalf("Expected %v pools, got %v", len(expected), len(data.Pools))
+	}
+
+	for i, v := range expected {
+		if data.Pools[i].Address != v {
+			t.Fatalf("Expected pool %v at %v, got %v", v, i, data.Pools[i].Address)
+		}
+	}
+}
+
+func TestPoolQuarantine(t *testing.T) {
+	pq := newPoolQuarantine(50 * time.Millisecond)
+	data := newQuarantineSnapshot()
+
+	// tolerant in short time
+	pq.onFailure(data, failingProbe(testPoolA))
+	filtered, skipped := pq.filter(data, failingProbe(testPoolA))
+	assertPools(t, filtered, testPoolA, testPoolB, testPoolC)
+
+	time.Sleep(60 * time.Millisecond)
+	pq.onFailure(data, failingProbe(testPoolA))
+
+	// skip events of quarantined pool, which are recorded to replay later
+	filtered, skipped = pq.filter(data, failingProbe(testPoolA))
+	assertPools(t, filtered, testPoolB, testPoolC)
+
+	if len(skipped) != 1 || skipped[0].Pool != testPoolA || skipped[0].Trades != 1 || len(skipped[0].Reason) == 0 {
+		t.Fatalf("Unexpected skipped pools %+v", skipped)
+	}
+
+	// release once succeeded
+	filtered, skipped = pq.filter(data, failingProbe())
+	assertPools(t, filtered, testPoolA, testPoolB, testPoolC)
+
+	if len(skipped) != 0 {
+		t.Fatalf("Unexpected skipped pools %+v", skipped)
+	}
+
+	if len(pq.quarantined) != 0 {
+		t.Fatalf("Expected no quarantined pool, got %v", len(pq.quarantined))
+	}
+}
+
+func TestPoolQuarantineGeneralFailure(t *testing.T) {
+	pq := newPoolQuarantine(time.Nanosecond)
+	data := newQuarantineSnapshot()
+
+	// all pools failed, e.g. RPC unavailable
+	pq.onFailure(data, failingProbe(testPoolA, testPoolB))
+	time.Sleep(time.Millisecond)
+	pq.onFailure(data, failingProbe(testPoolA, testPoolB))
+
+	if len(pq.quarantined) != 0 {
+		t.Fatalf("Expected no quarantined pool, got %v", len(pq.quarantined))
+	}
+
+	filtered, _ := pq.filter(data, failingProbe(testPoolA, testPoolB))
+	assertPools(t, filtered, testPoolA, testPoolB, testPoolC)
+}
+
+func TestPoolQuarantineRecoveredSuspect(t *testing.T) {
+	pq := newPoolQuarantine(50 * time.Millisecond)
+	data := newQuarantineSnapshot()
+
+	pq.onFailure(data, failingProbe(testPoolA))
+
+	// succeeded in between, which resets the failure duration
+	time.Sleep(60 * time.Millisecond)
+	pq.onSuccess()
+	pq.onFailure(data, failingProbe(testPoolA))
+
+	if len(pq.quarantined) != 0 {
+		t.Fatalf("Expected no quarantined pool, got %v", len(pq.quarantined))
+	}
+}
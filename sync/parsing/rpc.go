@@ -1,14 +1,25 @@
 package parsing
 
 import (
+	"encoding/json"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/openweb3/go-rpc-provider/interfaces"
 	providers "github.com/openweb3/go-rpc-provider/provider_wrapper"
 	"github.com/pkg/errors"
+	"github.com/v3-Swampy/points-service/blockchain"
 )
 
+// PagingOption is the option to query data of snapshot page by page.
+type PagingOption struct {
+	PageSize int // number of records per page, 0 for the default of contract parser
+	MaxPages int `default:"10000"` // maximum number of pages of a pool in snapshot
+}
+
 type Client struct {
 	interfaces.Provider
+
+	Paging PagingOption
 }
 
 func NewClient(url string, option ...providers.Option) (*Client, error) {
@@ -17,6 +28,8 @@ func NewClient(url string, option ...providers.Option) (*Client, error) {
 		return nil, errors.WithMessagef(err, "Failed to dial %v", url)
 	}
 
+	blockchain.HookRPCMetrics(provider, "parser")
+
 	return &Client{
 		Provider: provider,
 	}, nil
@@ -42,32 +55,28 @@ func (client *Client) GetTradeData(pool common.Address, timestamp int64, offset
 	return providers.Call[*PagingResult[TradeData]](client.Provider, "getHourlyTradeData", pool, timestamp, offset, limit[0])
 }
 
+// GetTradeDataAll returns all trade data of pool in snapshot.
 func (client *Client) GetTradeDataAll(pool common.Address, timestamp int64) ([]TradeData, error) {
-	var offset int
-	var all []TradeData
-
-	for {
-		result, err := client.GetTradeData(pool, timestamp, offset)
-		if err != nil {
-			return nil, errors.WithMessagef(err, "Failed to get trade data with offset %v", offset)
-		}
-
-		if result == nil {
-			return nil, nil
-		}
-
-		offset += len(result.Data)
+	return collectPages(client.Paging.MaxPages, func(offset int) (*PagingResult[TradeData], error) {
+		return client.getTradeDataPage(pool, timestamp, offset)
+	})
+}
 
-		if all == nil {
-			all = result.Data
-		} else {
-			all = append(all, result.Data...)
-		}
+// StreamTradeData emits trade data of pool in snapshot page by page, so that all data is not held in memory.
+func (client *Client) StreamTradeData(pool common.Address, timestamp int64, callback func(page []TradeData) error) error {
+	return streamPages(client.Paging.MaxPages, func(offset int) (*PagingResult[TradeData], error) {
+		return client.getTradeDataPage(pool, timestamp, offset)
+	}, func(page []TradeData, _ int) error {
+		return callback(page)
+	})
+}
 
-		if len(all) == result.Total {
-			return all, nil
-		}
+func (client *Client) getTradeDataPage(pool common.Address, timestamp int64, offset int) (*PagingResult[TradeData], error) {
+	if client.Paging.PageSize > 0 {
+		return client.GetTradeData(pool, timestamp, offset, client.Paging.PageSize)
 	}
+
+	return client.GetTradeData(pool, timestamp, offset)
 }
 
 func (client *Client) GetLiquidityData(pool common.Address, timestamp int64, offset int, limit ...int) (*PagingResult[LiquidityData], error) {
@@ -78,30 +87,114 @@ func (client *Client) GetLiquidityData(pool common.Address, timestamp int64, off
 	return providers.Call[*PagingResult[LiquidityData]](client.Provider, "getHourlyLiquidityData", pool, timestamp, offset, limit[0])
 }
 
+// GetLiquidityDataAll returns all liquidity data of pool in snapshot.
 func (client *Client) GetLiquidityDataAll(pool common.Address, timestamp int64) ([]LiquidityData, error) {
-	var offset int
-	var all []LiquidityData
+	return collectPages(client.Paging.MaxPages, func(offset int) (*PagingResult[LiquidityData], error) {
+		return client.getLiquidityDataPage(pool, timestamp, offset)
+	})
+}
+
+// StreamLiquidityData emits liquidity data of pool in snapshot page by page, so that all data is not held in memory.
+func (client *Client) StreamLiquidityData(pool common.Address, timestamp int64, callback func(page []LiquidityData) error) error {
+	return streamPages(client.Paging.MaxPages, func(offset int) (*PagingResult[LiquidityData], error) {
+		return client.getLiquidityDataPage(pool, timestamp, offset)
+	}, func(page []LiquidityData, _ int) error {
+		return callback(page)
+	})
+}
+
+func (client *Client) getLiquidityDataPage(pool common.Address, timestamp int64, offset int) (*PagingResult[LiquidityData], error) {
+	if client.Paging.PageSize > 0 {
+		return client.GetLiquidityData(pool, timestamp, offset, client.Paging.PageSize)
+	}
+
+	return client.GetLiquidityData(pool, timestamp, offset)
+}
+
+// streamPages fetches pages in order until total reached, and emits each page along with the total via callback.
+//
+// Note, it returns error if the parser returns an empty page before total reached, or returns more data than
+// total, or total changes between pages, or returns the same page repeatedly, or pages exceed the given maximum
+// number (0 for unlimited), so as to avoid endless loop or inconsistent data.
+func streamPages[T any](maxPages int, fetch func(offset int) (*PagingResult[T], error), callback func(page []T, total int) error) error {
+	total := -1
+	var lastPage string
+
+	for offset, pages := 0, 0; offset != total; pages++ {
+		if maxPages > 0 && pages >= maxPages {
+			return errors.Errorf("Too many pages, offset = %v, total = %v, maxPages = %v", offset, total, maxPages)
+		}
 
-	for {
-		result, err := client.GetLiquidityData(pool, timestamp, offset)
+		result, err := fetch(offset)
 		if err != nil {
-			return nil, errors.WithMessagef(err, "Failed to get liquidity data with offset %v", offset)
+			return errors.WithMessagef(err, "Failed to get page with offset %v", offset)
+		}
+
+		// no data at all
+		if result == nil && total < 0 {
+			return nil
 		}
 
 		if result == nil {
-			return nil, nil
+			return errors.Errorf("Page with offset %v not found, total = %v", offset, total)
+		}
+
+		if total >= 0 && result.Total != total {
+			return errors.Errorf("Total changed from %v to %v at offset %v", total, result.Total, offset)
+		}
+
+		total = result.Total
+
+		if offset >= total {
+			break
+		}
+
+		if len(result.Data) == 0 {
+			return errors.Errorf("Empty page returned with offset %v, total = %v", offset, total)
+		}
+
+		if offset+len(result.Data) > total {
+			return errors.Errorf("Page with offset %v exceeds total %v, size = %v", offset, total, len(result.Data))
+		}
+
+		// parser may ignore offset and return the same page again
+		page, err := json.Marshal(result.Data)
+		if err != nil {
+			return errors.WithMessage(err, "Failed to encode page")
+		}
+
+		if string(page) == lastPage {
+			return errors.Errorf("Same page returned repeatedly with offset %v, total = %v", offset, total)
+		}
+
+		lastPage = string(page)
+
+		if err = callback(result.Data, total); err != nil {
+			return err
 		}
 
 		offset += len(result.Data)
+	}
 
+	return nil
+}
+
+// collectPages fetches all pages and returns data in order.
+func collectPages[T any](maxPages int, fetch func(offset int) (*PagingResult[T], error)) ([]T, error) {
+	var all []T
+
+	err := streamPages(maxPages, fetch, func(page []T, total int) error {
 		if all == nil {
-			all = result.Data
-		} else {
-			all = append(all, result.Data...)
+			all = make([]T, 0, total)
 		}
 
-		if len(all) == result.Total {
-			return all, nil
-		}
+		all = append(all, page...)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	return all, nil
 }
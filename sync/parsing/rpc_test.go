@@ -0,0 +1,245 @@
+package parsing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// fakeParser is a fake JSON-RPC server of contract parser, which serves requests with the given handler.
+type fakeParser func(method string, params []any) (any, error)
+
+func newFakeParser(t *testing.T, handler fakeParser) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []any           `json:"params"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+		if result, err := handler(req.Method, req.Params); err != nil {
+			resp["error"] = map[string]any{"code": -32000, "message": err.Error()}
+		} else {
+			resp["result"] = result
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// pagingParams returns the offset and optional limit of paging request.
+func pagingParams(params []any) (offset, limit int) {
+	if len(params) > 2 {
+		offset = int(params[2].(float64))
+	}
+
+	if len(params) > 3 {
+		limit = int(params[3].(float64))
+	}
+
+	return
+}
+
+// pagedTrades creates a fake parser that serves trade data of the given total number page by page, where page
+// could be tampered with for the given offset.
+func pagedTrades(t *testing.T, total, defaultLimit int, tamper func(offset int, page *PagingResult[map[string]any])) *Client {
+	t.Helper()
+
+	server := newFakeParser(t, func(method string, params []any) (any, error) {
+		offset, limit := pagingParams(params)
+		if limit == 0 {
+			limit = defaultLimit
+		}
+
+		page := PagingResult[map[string]any]{Total: total, Data: []map[string]any{}}
+		for i := offset; i < min(offset+limit, total); i++ {
+			page.Data = append(page.Data, map[string]any{"user": fmt.Sprint(i)})
+		}
+
+		if tamper != nil {
+			tamper(offset, &page)
+		}
+
+		return page, nil
+	})
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(client.Close)
+
+	return client
+}
+
+func assertTrades(t *testing.T, data []TradeData, total int) {
+	t.Helper()
+
+	if len(data) != total {
+		t.Fatalf("Unexpected number of trades %v, expected = %v", len(data), total)
+	}
+
+	for i, v := range data {
+		if v.UserAddress != fmt.Sprint(i) {
+			t.Fatalf("Unexpected trade at %v, user = %v", i, v.UserAddress)
+		}
+	}
+}
+
+func TestGetDataAllPaging(t *testing.T) {
+	cases := []struct {
+		name         string
+		total        int
+		pageSize     int
+		defaultLimit int
+	}{
+		{"empty", 0, 3, 100},
+		{"single page", 2, 3, 100},
+		{"page boundary", 6, 3, 100},
+		{"short final page", 7, 3, 100},
+		{"default page size", 5, 0, 2},
+	}
+
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			client := pagedTrades(t, v.total, v.defaultLimit, nil)
+			client.Paging.PageSize = v.pageSize
+
+			data, err := client.GetTradeDataAll(common.Address{}, 3600)
+			if err != nil {
+				t.Fatalf("Failed to get trade data: %v", err)
+			}
+
+			assertTrades(t, data, v.total)
+		})
+	}
+}
+
+func TestGetDataAllNotFound(t *testing.T) {
+	server := newFakeParser(t, func(method string, params []any) (any, error) {
+		return nil, nil
+	})
+
+	client, err := NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	defer client.Close()
+
+	if data, err := client.GetLiquidityDataAll(common.Address{}, 3600); err != nil || data != nil {
+		t.Fatalf("Unexpected liquidity data %+v, err = %v", data, err)
+	}
+}
+
+func TestGetDataAllGuards(t *testing.T) {
+	cases := []struct {
+		name     string
+		maxPages int
+		tamper   func(offset int, page *PagingResult[map[string]any])
+	}{
+		{"empty page", 0, func(offset int, page *PagingResult[map[string]any]) {
+			if offset == 3 {
+				page.Data = nil
+			}
+		}},
+		{"total shrinks", 0, func(offset int, page *PagingResult[map[string]any]) {
+			if offset == 3 {
+				page.Total = 4
+			}
+		}},
+		{"total grows", 0, func(offset int, page *PagingResult[map[string]any]) {
+			if offset == 3 {
+				page.Total = 20
+			}
+		}},
+		{"exceeds total", 0, func(offset int, page *PagingResult[map[string]any]) {
+			if offset == 9 {
+				page.Data = append(page.Data, map[string]any{"user": "extra"})
+			}
+		}},
+		{"repeated page", 0, func(offset int, page *PagingResult[map[string]any]) {
+			// offset ignored
+			page.Data = []map[string]any{{"user": "0"}, {"user": "1"}, {"user": "2"}}
+		}},
+		{"max pages", 3, nil},
+	}
+
+	for _, v := range cases {
+		t.Run(v.name, func(t *testing.T) {
+			client := pagedTrades(t, 10, 100, v.tamper)
+			client.Paging = PagingOption{PageSize: 3, MaxPages: v.maxPages}
+
+			done := make(chan error, 1)
+			go func() {
+				_, err := client.GetTradeDataAll(common.Address{}, 3600)
+				done <- err
+			}()
+
+			select {
+			case err := <-done:
+				if err == nil {
+					t.Fatal("Guard not tripped")
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Endless paging")
+			}
+		})
+	}
+}
+
+func TestPagingOptionDefaults(t *testing.T) {
+	if opt := optionWithDefault[PollOption](); opt.Paging.MaxPages != 10000 {
+		t.Fatalf("Unexpected default max pages %v", opt.Paging.MaxPages)
+	}
+}
+
+func TestStreamDataPaging(t *testing.T) {
+	client := pagedTrades(t, 7, 100, nil)
+	client.Paging.PageSize = 3
+
+	var sizes []int
+	var data []TradeData
+	err := client.StreamTradeData(common.Address{}, 3600, func(page []TradeData) error {
+		sizes = append(sizes, len(page))
+		data = append(data, page...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to stream trade data: %v", err)
+	}
+
+	if fmt.Sprint(sizes) != "[3 3 1]" {
+		t.Fatalf("Unexpected page sizes %v", sizes)
+	}
+
+	assertTrades(t, data, 7)
+
+	// abort streaming once callback failed
+	var pages int
+	err = client.StreamTradeData(common.Address{}, 3600, func(page []TradeData) error {
+		pages++
+		return errors.New("callback failure")
+	})
+	if err == nil || pages != 1 {
+		t.Fatalf("Streaming not aborted, pages = %v, err = %v", pages, err)
+	}
+}